@@ -0,0 +1,117 @@
+package epay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount 金额，以分为单位存储，格式化时固定保留两位小数
+// 例如 Amount(250) 表示 2.50 元
+type Amount int64
+
+var errInvalidAmount = errors.New("金额格式错误")
+
+// ParseAmount 解析金额字符串
+// 仅支持非负数且最多两位小数，如 "2"、"2.5"、"2.50"
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" || !isDigits(intPart) {
+		return 0, fmt.Errorf("%w: %q", errInvalidAmount, s)
+	}
+	if hasDot && (fracPart == "" || len(fracPart) > 2 || !isDigits(fracPart)) {
+		return 0, fmt.Errorf("%w: %q，最多保留两位小数", errInvalidAmount, s)
+	}
+
+	cents := int64(0)
+	if fracPart != "" {
+		cents, _ = strconv.ParseInt(fracPart, 10, 64)
+		if len(fracPart) == 1 {
+			cents *= 10
+		}
+	}
+	yuan, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || yuan > (math.MaxInt64-cents)/100 {
+		return 0, fmt.Errorf("%w: %q", errInvalidAmount, s)
+	}
+	return Amount(yuan*100 + cents), nil
+}
+
+// MustParseAmount 解析金额字符串，格式错误时panic，适用于常量金额
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Cents 返回以分为单位的金额
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// String 格式化为两位小数，如 "2.50"
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalText 实现 encoding.TextMarshaler，用于表单参数
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，空字符串视为0
+func (a *Amount) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) == 0 {
+		*a = 0
+		return nil
+	}
+	v, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// MarshalJSON 序列化为字符串，如 "2.50"
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON 兼容平台以字符串或数字返回的金额
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*a = 0
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return a.UnmarshalText([]byte(s))
+	}
+	return a.UnmarshalText(data)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
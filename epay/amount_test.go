@@ -0,0 +1,60 @@
+package epay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	asserts := assert.New(t)
+	for input, expected := range map[string]string{
+		"2":    "2.00",
+		"2.5":  "2.50",
+		"2.50": "2.50",
+		"0.01": "0.01",
+		"100":  "100.00",
+
+		"92233720368547758.07": "92233720368547758.07",
+	} {
+		a, err := ParseAmount(input)
+		asserts.NoError(err, input)
+		asserts.Equal(expected, a.String(), input)
+	}
+	asserts.Equal(int64(250), MustParseAmount("2.5").Cents())
+
+	for _, input := range []string{"", "-1", "2.500", "2.", ".5", "1e3", "abc", "1,00", "92233720368547758.08", "92233720368547758.99", "92233720368547759"} {
+		_, err := ParseAmount(input)
+		asserts.Error(err, input)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	asserts := assert.New(t)
+
+	var res ApiOrderQueryRes
+	asserts.NoError(json.Unmarshal([]byte(`{"money":"2.5","refundmoney":1}`), &res))
+	asserts.Equal(Amount(250), res.Money)
+	asserts.Equal(Amount(100), res.RefundMoney)
+
+	asserts.NoError(json.Unmarshal([]byte(`{"money":"","refundmoney":null}`), &res))
+	asserts.Equal(Amount(0), res.Money)
+	asserts.Equal(Amount(0), res.RefundMoney)
+
+	asserts.Error(json.Unmarshal([]byte(`{"money":"2.505"}`), &res))
+
+	data, _ := json.Marshal(struct {
+		Money Amount `json:"money"`
+	}{Money: 250})
+	asserts.Equal(`{"money":"2.50"}`, string(data))
+}
+
+func TestVerifyDecodesAmount(t *testing.T) {
+	asserts := assert.New(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost")
+	res, err := client.Verify(GenerateParams(map[string]string{"money": "1.5"}, "KEY", SignTypeMD5))
+	asserts.NoError(err)
+	asserts.True(res.VerifyStatus)
+	asserts.Equal(MustParseAmount("1.50"), res.Money)
+}
@@ -1,6 +1,10 @@
 package epay
 
-import "net/url"
+import (
+	"context"
+	"errors"
+	"net/url"
+)
 
 var _ Service = (*Client)(nil)
 
@@ -8,22 +12,60 @@ var _ Service = (*Client)(nil)
 type Service interface {
 	// 创建订单
 	CreateOrder(args *CreateOrderArgs) (string, map[string]string, error)
+	CreateOrderWithContext(ctx context.Context, args *CreateOrderArgs) (string, map[string]string, error)
 	// API创建订单
 	ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error)
+	ApiCreateOrderWithContext(ctx context.Context, args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error)
 	// 查询订单
 	QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, error)
+	QueryOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*ApiOrderQueryRes, error)
+	// 订单退款
+	Refund(args *RefundArgs) (*RefundRes, error)
+	RefundWithContext(ctx context.Context, args *RefundArgs) (*RefundRes, error)
+	// 查询退款
+	QueryRefund(refundNo, outRefundNo string) (*RefundQueryRes, error)
+	QueryRefundWithContext(ctx context.Context, refundNo, outRefundNo string) (*RefundQueryRes, error)
+	// 关闭未支付订单
+	CloseOrder(tradeNo, outTradeNo string) (*CloseOrderRes, error)
+	CloseOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*CloseOrderRes, error)
+	// 查询商户信息
+	MerchantInfo() (*MerchantInfoRes, error)
+	MerchantInfoWithContext(ctx context.Context) (*MerchantInfoRes, error)
+	// 分页查询订单列表
+	ListOrders(args *ListOrdersArgs) (*ListOrdersRes, error)
+	ListOrdersWithContext(ctx context.Context, args *ListOrdersArgs) (*ListOrdersRes, error)
+	// 查询结算记录
+	ListSettlements() (*ListSettlementsRes, error)
+	ListSettlementsWithContext(ctx context.Context) (*ListSettlementsRes, error)
 	// Verify 验证回调参数是否符合签名
 	Verify(params map[string]string) (*VerifyRes, error)
 }
 
 // 创建一个新的易支付客户端
-func NewClient(config *Config, baseUrl string) (*Client, error) {
+func NewClient(config *Config, baseUrl string, opts ...ClientOption) (*Client, error) {
+	if config == nil {
+		return nil, errors.New("必须提供客户端配置")
+	}
 	u, err := url.Parse(baseUrl)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
+	c := &Client{
 		Config:  config,
 		BaseUrl: u,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.initEndpoints(); err != nil {
+		return nil, err
+	}
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+	// 提前解析密钥，密钥格式错误时尽早失败
+	if err := c.loadKeys(); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
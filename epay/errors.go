@@ -0,0 +1,104 @@
+package epay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// 当前接口版本不支持该操作
+	ErrUnsupported = errors.New("当前接口版本不支持该操作")
+	// 平台响应签名验证失败
+	ErrInvalidResponseSign = errors.New("响应签名验证失败")
+	// 回调通知时间戳超出允许范围
+	ErrNotifyExpired = errors.New("回调通知已过期")
+	// 回调通知重复
+	ErrNotifyReplayed = errors.New("回调通知重复")
+	// 请求不是浏览器的同步跳转
+	ErrNotReturnRequest = errors.New("非同步跳转请求")
+
+	// 以下错误用于 errors.Is 判断平台返回的 *APIError
+	// 请求签名错误或商户密钥不正确，同步跳转验签失败时也返回该错误
+	ErrInvalidSign = errors.New("签名校验失败")
+	// 订单不存在
+	ErrOrderNotFound = errors.New("订单不存在")
+	// 商户订单号重复
+	ErrDuplicateOrder = errors.New("商户订单号已存在")
+	// 商户余额不足
+	ErrInsufficientBalance = errors.New("余额不足")
+)
+
+// APIError 平台返回的业务错误
+type APIError struct {
+	// 返回状态码
+	Code int
+	// 返回信息
+	Message string
+	// 接口版本
+	Version Version
+	// 原始响应内容
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("epay %s error: code=%d, msg=%s", e.Version, e.Code, e.Message)
+}
+
+// Is 根据平台返回信息匹配常见错误
+func (e *APIError) Is(target error) bool {
+	msg := e.Message
+	switch target {
+	case ErrInvalidSign:
+		return strings.Contains(msg, "签名") || strings.Contains(msg, "KEY校验") || strings.Contains(strings.ToLower(msg), "sign")
+	case ErrOrderNotFound:
+		return strings.Contains(msg, "订单") && strings.Contains(msg, "不存在")
+	case ErrDuplicateOrder:
+		return strings.Contains(msg, "订单号已存在") || strings.Contains(msg, "订单号重复")
+	case ErrInsufficientBalance:
+		return strings.Contains(msg, "余额不足")
+	}
+	return false
+}
+
+// StatusError 网关返回的HTTP错误状态
+type StatusError struct {
+	// HTTP状态码
+	StatusCode int
+	// 原始响应内容
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("epay http error: status=%d", e.StatusCode)
+}
+
+// ExpectationError 回调通知与预期订单不一致
+type ExpectationError struct {
+	Mismatches []Mismatch
+}
+
+func (e *ExpectationError) Error() string {
+	parts := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		parts = append(parts, fmt.Sprintf("%s(预期 %q, 实际 %q)", m.Field, m.Expected, m.Actual))
+	}
+	return "回调通知与预期订单不一致: " + strings.Join(parts, "; ")
+}
+
+// 检查响应状态码，v1是1成功，v2是0成功，失败时返回 *APIError
+func checkCode(version Version, code int, message string, body []byte) error {
+	successCode := 1
+	if version == VersionV2 {
+		successCode = 0
+	}
+	if code == successCode {
+		return nil
+	}
+	return &APIError{
+		Code:    code,
+		Message: message,
+		Version: version,
+		Body:    body,
+	}
+}
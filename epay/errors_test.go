@@ -0,0 +1,27 @@
+package epay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCode(t *testing.T) {
+	asserts := assert.New(t)
+
+	asserts.NoError(checkCode(VersionV1, 1, "succ", nil))
+	asserts.NoError(checkCode(VersionV2, 0, "", nil))
+
+	err := checkCode(VersionV1, -1, "订单号不存在", []byte(`{"code":-1}`))
+	var apiErr *APIError
+	asserts.True(errors.As(err, &apiErr))
+	asserts.Equal(VersionV1, apiErr.Version)
+	asserts.Equal(-1, apiErr.Code)
+	asserts.ErrorIs(err, ErrOrderNotFound)
+	asserts.NotErrorIs(err, ErrInvalidSign)
+
+	asserts.ErrorIs(checkCode(VersionV2, 1, "签名校验失败", nil), ErrInvalidSign)
+	asserts.ErrorIs(checkCode(VersionV2, 1, "该商户订单号已存在", nil), ErrDuplicateOrder)
+	asserts.ErrorIs(checkCode(VersionV2, 1, "商户余额不足", nil), ErrInsufficientBalance)
+}
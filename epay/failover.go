@@ -0,0 +1,190 @@
+package epay
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+// 熔断器默认配置
+const (
+	DefaultBreakerThreshold = 3                // 连续失败次数达到该值后熔断
+	DefaultBreakerCooldown  = 30 * time.Second // 熔断后暂停使用的时长
+)
+
+// EndpointStatus 网关地址的健康状态
+type EndpointStatus struct {
+	// 网关地址
+	URL string
+	// 熔断器是否关闭，熔断期间请求会优先使用其他地址
+	Healthy bool
+	// 连续失败次数
+	Failures int
+	// 最近一次失败的原因
+	LastError error
+	// 熔断结束时间
+	OpenUntil time.Time
+}
+
+// 网关地址及其熔断状态
+type endpoint struct {
+	baseUrl *url.URL
+
+	mu        sync.Mutex
+	failures  int
+	lastErr   error
+	openUntil time.Time
+}
+
+// 熔断器是否允许请求，熔断时长结束后放行请求进行探测
+func (e *endpoint) available(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.openUntil)
+}
+
+func (e *endpoint) success() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures = 0
+	e.lastErr = nil
+	e.openUntil = time.Time{}
+}
+
+func (e *endpoint) failure(err error, threshold int, cooldown time.Duration, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	e.lastErr = err
+	if e.failures >= threshold {
+		e.openUntil = now.Add(cooldown)
+	}
+}
+
+func (e *endpoint) status(now time.Time) EndpointStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return EndpointStatus{
+		URL:       e.baseUrl.String(),
+		Healthy:   !now.Before(e.openUntil),
+		Failures:  e.failures,
+		LastError: e.lastErr,
+		OpenUntil: e.openUntil,
+	}
+}
+
+// Endpoints 返回所有网关地址的健康状态，按优先级排列
+func (c *Client) Endpoints() []EndpointStatus {
+	now := time.Now()
+	endpoints := c.getEndpoints()
+	statuses := make([]EndpointStatus, 0, len(endpoints))
+	for _, e := range endpoints {
+		statuses = append(statuses, e.status(now))
+	}
+	return statuses
+}
+
+// 初始化网关地址列表，主地址在前，备用地址按配置顺序排列
+func (c *Client) initEndpoints() error {
+	c.endpoints = []*endpoint{{baseUrl: c.BaseUrl}}
+	for _, raw := range c.backupUrls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		c.endpoints = append(c.endpoints, &endpoint{baseUrl: u})
+	}
+	return nil
+}
+
+// 获取网关地址列表，未通过 NewClient 创建的客户端只使用 BaseUrl
+func (c *Client) getEndpoints() []*endpoint {
+	if len(c.endpoints) == 0 {
+		return []*endpoint{{baseUrl: c.BaseUrl}}
+	}
+	return c.endpoints
+}
+
+// 获取熔断器配置，未设置时使用默认值
+func (c *Client) breakerConfig() (int, time.Duration) {
+	threshold, cooldown := c.breakerThreshold, c.breakerCooldown
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return threshold, cooldown
+}
+
+// 按优先级依次尝试各网关地址，网络错误或5xx响应时切换到下一个地址
+// 跳过熔断中的地址，所有地址均熔断时仍按顺序尝试
+// idempotent为false时仅在请求发出前失败（DNS解析、建立连接失败）才切换，避免重复下单
+func (c *Client) failover(ctx context.Context, apiPath string, idempotent bool, build func(apiUrl *url.URL) (*http.Request, error)) ([]byte, error) {
+	now := time.Now()
+	endpoints := c.getEndpoints()
+	threshold, cooldown := c.breakerConfig()
+	candidates := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if e.available(now) {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = endpoints
+	}
+
+	var lastErr error
+	for _, e := range candidates {
+		u := *e.baseUrl
+		u.Path = path.Join(u.Path, apiPath)
+		req, err := build(&u)
+		if err != nil {
+			return nil, err
+		}
+		body, err := c.do(req)
+		if err == nil {
+			e.success()
+			return body, nil
+		}
+		if !isGatewayFailure(err) {
+			// 无法判断网关状态的错误（如读取响应失败）不改变熔断器状态
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		e.failure(err, threshold, cooldown, time.Now())
+		if !idempotent && !isDialError(err) {
+			// 网关可能已收到请求，切换地址可能导致重复处理
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// 是否为网关不可用导致的错误：网络错误或5xx响应
+func isGatewayFailure(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// 是否为请求发出前的错误，此时网关一定没有收到请求
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
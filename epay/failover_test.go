@@ -0,0 +1,156 @@
+package epay
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailover(t *testing.T) {
+	asserts := assert.New(t)
+	var primaryHits int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryHits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mapi.php" {
+			w.Write([]byte(`{"code":-1,"msg":"商户订单号已存在"}`))
+			return
+		}
+		asserts.Equal("T1", r.URL.Query().Get("trade_no"))
+		w.Write([]byte(`{"code":1,"trade_no":"T1","money":"1.00","status":1}`))
+	}))
+	defer backup.Close()
+
+	client, err := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, primary.URL,
+		WithBackupURLs(down.URL, backup.URL), WithCircuitBreaker(2, time.Minute))
+	asserts.NoError(err)
+
+	res, err := client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal("1.00", res.Money.String())
+
+	endpoints := client.Endpoints()
+	asserts.Len(endpoints, 3)
+	asserts.Equal(1, endpoints[0].Failures)
+	asserts.True(endpoints[0].Healthy)
+	var statusErr *StatusError
+	asserts.True(errors.As(endpoints[0].LastError, &statusErr))
+	asserts.Equal(http.StatusBadGateway, statusErr.StatusCode)
+	asserts.Equal(1, endpoints[1].Failures)
+	asserts.Equal(0, endpoints[2].Failures)
+
+	// 连续失败达到阈值后熔断，后续请求跳过该地址
+	_, err = client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.False(client.Endpoints()[0].Healthy)
+	_, err = client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal(int32(2), atomic.LoadInt32(&primaryHits))
+
+	// 所有地址均不可用时返回最后一次错误
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, primary.URL, WithBackupURLs(down.URL))
+	_, err = client.QueryOrder("T1", "")
+	asserts.Error(err)
+
+	// 业务错误不触发切换
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, backup.URL, WithBackupURLs(primary.URL))
+	notifyURL, _ := url.Parse("http://localhost/notify")
+	_, err = client.ApiCreateOrder(&ApiCreateOrderArgs{Type: "alipay", OutTradeNo: "O1", NotifyURL: notifyURL, Money: 100})
+	asserts.ErrorIs(err, ErrDuplicateOrder)
+	asserts.Equal(0, client.Endpoints()[0].Failures)
+	asserts.Equal(int32(3), atomic.LoadInt32(&primaryHits))
+}
+
+func TestFailoverCreateOrder(t *testing.T) {
+	asserts := assert.New(t)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"code":1,"trade_no":"PRIMARY"}`))
+	}))
+	defer slow.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	var backupHits int32
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&backupHits, 1)
+		w.Write([]byte(`{"code":1,"trade_no":"BACKUP"}`))
+	}))
+	defer backup.Close()
+
+	notifyURL, _ := url.Parse("http://localhost/notify")
+	args := &ApiCreateOrderArgs{Type: "alipay", OutTradeNo: "O1", NotifyURL: notifyURL, Money: 100}
+
+	// 读取响应超时时网关可能已创建订单，不切换地址
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, slow.URL,
+		WithBackupURLs(backup.URL), WithTimeout(50*time.Millisecond))
+	_, err := client.ApiCreateOrder(args)
+	asserts.Error(err)
+	asserts.Equal(int32(0), atomic.LoadInt32(&backupHits))
+	asserts.Equal(1, client.Endpoints()[0].Failures)
+
+	// 连接失败时请求未发出，可以切换
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, down.URL, WithBackupURLs(backup.URL))
+	res, err := client.ApiCreateOrder(args)
+	asserts.NoError(err)
+	asserts.Equal("BACKUP", res.TradeNo)
+
+	// 调用方保证幂等时超时也切换
+	args.Idempotent = true
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, slow.URL,
+		WithBackupURLs(backup.URL), WithTimeout(50*time.Millisecond))
+	res, err = client.ApiCreateOrder(args)
+	asserts.NoError(err)
+	asserts.Equal("BACKUP", res.TradeNo)
+	asserts.Equal(int32(2), atomic.LoadInt32(&backupHits))
+}
+
+func TestFailoverWithoutNewClient(t *testing.T) {
+	asserts := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":1,"trade_no":"T1","money":"1.00","status":1}`))
+	}))
+	defer server.Close()
+
+	// 直接构造的客户端使用BaseUrl发送请求
+	baseUrl, _ := url.Parse(server.URL)
+	client := &Client{Config: &Config{PartnerID: "1000", Key: "KEY"}, BaseUrl: baseUrl}
+	res, err := client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal("T1", res.TradeNo)
+	asserts.Len(client.Endpoints(), 1)
+}
+
+func TestFailoverKeepsBreakerOnReadError(t *testing.T) {
+	asserts := assert.New(t)
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		// 声明的长度大于实际内容，读取响应时返回unexpected EOF
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(`{"code":1`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL)
+	_, err := client.QueryOrder("T1", "")
+	asserts.Error(err)
+	asserts.Equal(1, client.Endpoints()[0].Failures)
+
+	_, err = client.QueryOrder("T1", "")
+	asserts.Error(err)
+	asserts.Equal(1, client.Endpoints()[0].Failures)
+}
@@ -0,0 +1,92 @@
+package epay
+
+import "context"
+
+const (
+	DefaultOrderPageSize = 50 // 订单列表默认每页数量
+	MaxOrderPageSize     = 50 // 订单列表每页最大数量，超出时平台按最大数量返回
+)
+
+// OrderIterator 订单列表迭代器，自动处理分页
+//
+//	it := client.IterOrders(0)
+//	for it.Next() {
+//		order := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//		// 处理错误
+//	}
+type OrderIterator struct {
+	ctx      context.Context
+	client   *Client
+	pageSize int
+	offset   int
+	buf      []ApiOrderQueryRes
+	cur      *ApiOrderQueryRes
+	done     bool
+	err      error
+}
+
+// IterOrders 创建订单列表迭代器，pageSize不大于0时使用默认值，超过 MaxOrderPageSize 时按最大值处理
+func (c *Client) IterOrders(pageSize int) *OrderIterator {
+	return c.IterOrdersWithContext(context.Background(), pageSize)
+}
+
+// IterOrdersWithContext 同IterOrders，ctx作用于迭代过程中的每次分页请求
+func (c *Client) IterOrdersWithContext(ctx context.Context, pageSize int) *OrderIterator {
+	if pageSize <= 0 {
+		pageSize = DefaultOrderPageSize
+	}
+	// 迭代器以返回数量少于pageSize判断最后一页，不能超过平台的每页上限
+	if pageSize > MaxOrderPageSize {
+		pageSize = MaxOrderPageSize
+	}
+	return &OrderIterator{ctx: ctx, client: c, pageSize: pageSize}
+}
+
+// Next 移动到下一个订单，没有更多订单或发生错误时返回false
+func (it *OrderIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.buf) == 0 {
+			return false
+		}
+	}
+	it.cur = &it.buf[0]
+	it.buf = it.buf[1:]
+	return true
+}
+
+// Order 返回当前订单
+func (it *OrderIterator) Order() *ApiOrderQueryRes {
+	return it.cur
+}
+
+// Err 返回迭代过程中发生的错误
+func (it *OrderIterator) Err() error {
+	return it.err
+}
+
+// 拉取下一页
+func (it *OrderIterator) fetch() error {
+	res, err := it.client.ListOrdersWithContext(it.ctx, &ListOrdersArgs{Offset: it.offset, Limit: it.pageSize})
+	if err != nil {
+		return err
+	}
+
+	it.buf = res.Data
+	it.offset += len(res.Data)
+	if len(res.Data) < it.pageSize {
+		it.done = true
+	}
+	return nil
+}
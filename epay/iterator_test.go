@@ -0,0 +1,66 @@
+package epay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderIterator(t *testing.T) {
+	asserts := assert.New(t)
+	total := 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asserts.Equal("orders", r.URL.Query().Get("act"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var data []ApiOrderQueryRes
+		for i := offset; i < total && i < offset+limit; i++ {
+			data = append(data, ApiOrderQueryRes{TradeNo: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(ListOrdersRes{Code: 1, Data: data})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL)
+	asserts.NoError(err)
+
+	var tradeNos []string
+	it := client.IterOrders(2)
+	for it.Next() {
+		tradeNos = append(tradeNos, it.Order().TradeNo)
+	}
+	asserts.NoError(it.Err())
+	asserts.Equal([]string{"0", "1", "2", "3", "4"}, tradeNos)
+}
+
+func TestOrderIteratorPageSizeCap(t *testing.T) {
+	asserts := assert.New(t)
+	total := 120
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		// 平台每页最多返回50条
+		if limit > 50 {
+			limit = 50
+		}
+		var data []ApiOrderQueryRes
+		for i := offset; i < total && i < offset+limit; i++ {
+			data = append(data, ApiOrderQueryRes{TradeNo: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(ListOrdersRes{Code: 1, Data: data})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL)
+	count := 0
+	it := client.IterOrders(100)
+	for it.Next() {
+		count++
+	}
+	asserts.NoError(it.Err())
+	asserts.Equal(total, count)
+}
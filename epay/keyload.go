@@ -0,0 +1,157 @@
+package epay
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadPrivateKeyPEM 加载RSA私钥
+// 支持完整PEM、不带头尾的base64及DER格式，自动识别PKCS#1与PKCS#8
+func LoadPrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
+	der, err := keyDER(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.New("私钥既不是PKCS#1也不是PKCS#8格式")
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("私钥类型错误，仅支持RSA私钥")
+	}
+	return rsaKey, nil
+}
+
+// LoadPrivateKeyFile 从文件加载RSA私钥
+func LoadPrivateKeyFile(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadPrivateKeyPEM(data)
+}
+
+// LoadPublicKeyPEM 加载RSA公钥
+// 支持完整PEM、不带头尾的base64及DER格式，自动识别PKIX、PKCS#1及X.509证书
+func LoadPublicKeyPEM(data []byte) (*rsa.PublicKey, error) {
+	der, err := keyDER(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("公钥类型错误，仅支持RSA公钥")
+		}
+		return rsaKey, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	return publicKeyFromCertificateDER(der)
+}
+
+// LoadPublicKeyFile 从文件加载RSA公钥或证书
+func LoadPublicKeyFile(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadPublicKeyPEM(data)
+}
+
+// LoadPublicKeyFromCertificate 从X.509证书（PEM或DER）中提取RSA公钥
+func LoadPublicKeyFromCertificate(data []byte) (*rsa.PublicKey, error) {
+	der, err := keyDER(data)
+	if err != nil {
+		return nil, err
+	}
+	return publicKeyFromCertificateDER(der)
+}
+
+func publicKeyFromCertificateDER(der []byte) (*rsa.PublicKey, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.New("无法识别的公钥格式")
+	}
+	rsaKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("证书公钥类型错误，仅支持RSA公钥")
+	}
+	return rsaKey, nil
+}
+
+// FromEnv 从环境变量读取配置，prefix为空时使用 "EPAY_"
+//   - {prefix}PID: 商户ID
+//   - {prefix}KEY 或 {prefix}KEY_FILE: MD5密钥或RSA私钥
+//   - {prefix}PUBLIC_KEY 或 {prefix}PUBLIC_KEY_FILE: 平台公钥或证书
+//   - {prefix}VERSION: 接口版本，可选 v1、v2、auto
+func FromEnv(prefix string) (*Config, error) {
+	if prefix == "" {
+		prefix = "EPAY_"
+	}
+	config := &Config{
+		PartnerID: os.Getenv(prefix + "PID"),
+		Key:       os.Getenv(prefix + "KEY"),
+		PublicKey: os.Getenv(prefix + "PUBLIC_KEY"),
+		Version:   Version(os.Getenv(prefix + "VERSION")),
+	}
+	if config.PartnerID == "" {
+		return nil, fmt.Errorf("缺少环境变量 %sPID", prefix)
+	}
+
+	if path := os.Getenv(prefix + "KEY_FILE"); path != "" {
+		privateKey, err := LoadPrivateKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取商户私钥文件失败: %w", err)
+		}
+		config.RSAPrivateKey = privateKey
+	}
+	if path := os.Getenv(prefix + "PUBLIC_KEY_FILE"); path != "" {
+		publicKey, err := LoadPublicKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取平台公钥文件失败: %w", err)
+		}
+		config.RSAPublicKey = publicKey
+	}
+	// RSA私钥须配合平台公钥使用，否则会以空的MD5密钥签名
+	if config.RSAPrivateKey != nil && config.PublicKey == "" && config.RSAPublicKey == nil {
+		return nil, fmt.Errorf("设置 %sKEY_FILE 时必须同时设置 %sPUBLIC_KEY 或 %sPUBLIC_KEY_FILE", prefix, prefix, prefix)
+	}
+	if config.Key == "" && config.RSAPrivateKey == nil {
+		return nil, fmt.Errorf("缺少环境变量 %sKEY 或 %sKEY_FILE", prefix, prefix)
+	}
+	return config, nil
+}
+
+// 将密钥内容统一转换为DER编码
+func keyDER(data []byte) ([]byte, error) {
+	// 兼容环境变量中以字面量\n表示的换行
+	text := bytes.ReplaceAll(bytes.TrimSpace(data), []byte(`\n`), []byte("\n"))
+	if block, _ := pem.Decode(text); block != nil {
+		return block.Bytes, nil
+	}
+	// DER编码以ASN.1 SEQUENCE开头
+	if len(data) > 0 && data[0] == 0x30 {
+		return data, nil
+	}
+	// 不带头尾的base64，忽略其中的空白字符
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(text)), ""))
+	if err != nil || len(der) == 0 {
+		return nil, errors.New("无法识别的密钥格式，仅支持PEM、base64及DER")
+	}
+	return der, nil
+}
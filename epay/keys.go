@@ -0,0 +1,68 @@
+package epay
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 初始化RSA签名使用的签名器与验签器，仅解析一次
+// 优先使用 Config.Signer、Config.Verifier，其次为已解析的RSA密钥，最后解析Key、PublicKey
+func (c *Client) loadKeys() error {
+	c.keyOnce.Do(func() {
+		if !c.useRSA() {
+			return
+		}
+
+		c.signer = c.Config.Signer
+		if c.signer == nil {
+			privateKey := c.Config.RSAPrivateKey
+			if privateKey == nil {
+				var err error
+				if privateKey, err = ParsePrivateKey(c.Config.Key); err != nil {
+					c.keyErr = fmt.Errorf("解析商户私钥失败: %w", err)
+					return
+				}
+			}
+			c.signer = &RSASigner{Key: privateKey}
+		}
+
+		c.verifier = c.Config.Verifier
+		if c.verifier == nil {
+			publicKey := c.Config.RSAPublicKey
+			if publicKey == nil {
+				var err error
+				if publicKey, err = ParsePublicKey(c.Config.PublicKey); err != nil {
+					c.keyErr = fmt.Errorf("解析平台公钥失败: %w", err)
+					return
+				}
+			}
+			c.verifier = &RSAVerifier{Key: publicKey}
+		}
+	})
+	return c.keyErr
+}
+
+// 生成加签参数，RSA使用配置的签名器，MD5使用商户密钥
+func (c *Client) signParams(params map[string]string, signType string) (map[string]string, error) {
+	if signType != SignTypeRSA {
+		return SignParams(params, MD5Signer(c.Config.Key))
+	}
+	if err := c.loadKeys(); err != nil {
+		return nil, err
+	}
+	if c.signer == nil {
+		return nil, errors.New("未配置商户私钥或签名器，无法使用RSA签名")
+	}
+	return SignParams(params, c.signer)
+}
+
+// 使用配置的验签器验证平台RSA签名
+func (c *Client) rsaVerify(content, sign string) (bool, error) {
+	if err := c.loadKeys(); err != nil {
+		return false, err
+	}
+	if c.verifier == nil {
+		return false, errors.New("未配置平台公钥，无法验证RSA签名")
+	}
+	return c.verifier.Verify(content, sign)
+}
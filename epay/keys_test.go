@@ -0,0 +1,179 @@
+package epay
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientParsesKeys(t *testing.T) {
+	asserts := assert.New(t)
+	privateKey, publicKey := genTestKeyPair(t)
+
+	client, err := NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey}, "http://localhost")
+	asserts.NoError(err)
+	asserts.NotNil(client.signer)
+	asserts.NotNil(client.verifier)
+
+	_, err = NewClient(&Config{PartnerID: "1000", Key: "bad", PublicKey: publicKey}, "http://localhost")
+	asserts.ErrorContains(err, "解析商户私钥失败")
+
+	_, err = NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: "bad"}, "http://localhost")
+	asserts.ErrorContains(err, "解析平台公钥失败")
+
+	// V1仅使用MD5密钥，不解析RSA密钥
+	_, err = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost")
+	asserts.NoError(err)
+}
+
+func TestLoadKeys(t *testing.T) {
+	asserts := assert.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	asserts.NoError(err)
+
+	pkcs1 := x509.MarshalPKCS1PrivateKey(key)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(key)
+	pkix, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	asserts.NoError(err)
+
+	// 私钥：PEM(PKCS#1/PKCS#8)、带换行的base64、DER、字面量\n
+	pkcs8PEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+	for name, data := range map[string][]byte{
+		"pkcs1 pem":    pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs1}),
+		"pkcs8 pem":    pkcs8PEM,
+		"pkcs8 base64": []byte(base64.StdEncoding.EncodeToString(pkcs8)),
+		"pkcs1 der":    pkcs1,
+		"escaped":      []byte(strings.ReplaceAll(string(pkcs8PEM), "\n", `\n`)),
+	} {
+		loaded, err := LoadPrivateKeyPEM(data)
+		if asserts.NoError(err, name) {
+			asserts.True(key.Equal(loaded), name)
+		}
+	}
+
+	// 公钥：PKIX、PKCS#1、证书
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	for name, data := range map[string][]byte{
+		"pkix pem":    pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}),
+		"pkix base64": []byte(base64.StdEncoding.EncodeToString(pkix)),
+		"pkcs1 pem":   pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}),
+		"certificate": certPEM,
+	} {
+		loaded, err := LoadPublicKeyPEM(data)
+		if asserts.NoError(err, name) {
+			asserts.True(key.PublicKey.Equal(loaded), name)
+		}
+	}
+	fromCert, err := LoadPublicKeyFromCertificate(cert)
+	asserts.NoError(err)
+	asserts.True(key.PublicKey.Equal(fromCert))
+
+	// 环境变量及文件
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key.pem")
+	certFile := filepath.Join(dir, "cert.pem")
+	asserts.NoError(os.WriteFile(keyFile, pkcs8PEM, 0o600))
+	asserts.NoError(os.WriteFile(certFile, certPEM, 0o600))
+	t.Setenv("TEST_EPAY_PID", "1000")
+	t.Setenv("TEST_EPAY_KEY_FILE", keyFile)
+	t.Setenv("TEST_EPAY_PUBLIC_KEY_FILE", certFile)
+
+	config, err := FromEnv("TEST_EPAY_")
+	asserts.NoError(err)
+	asserts.Equal("1000", config.PartnerID)
+	client, err := NewClient(config, "http://localhost")
+	asserts.NoError(err)
+	asserts.True(client.useRSA())
+	asserts.True(key.Equal(client.signer.(*RSASigner).Key))
+
+	// 只有RSA私钥而没有平台公钥时拒绝，避免以空密钥进行MD5签名
+	t.Setenv("TEST_EPAY_PUBLIC_KEY_FILE", "")
+	_, err = FromEnv("TEST_EPAY_")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000", RSAPrivateKey: key}, "http://localhost")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000"}, "http://localhost")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000", Key: "KEY", Signer: &CryptoSigner{Signer: key}}, "http://localhost")
+	asserts.Error(err)
+}
+
+func TestCryptoSigner(t *testing.T) {
+	asserts := assert.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	asserts.NoError(err)
+
+	// 私钥仅以 crypto.Signer 形式提供，Key留空
+	client, err := NewClient(&Config{
+		PartnerID:    "1000",
+		Signer:       &CryptoSigner{Signer: key},
+		RSAPublicKey: &key.PublicKey,
+	}, "http://localhost")
+	asserts.NoError(err)
+
+	params, err := client.signParams(map[string]string{"pid": "1000", "money": "1.00"}, SignTypeRSA)
+	asserts.NoError(err)
+	asserts.Equal(SignTypeRSA, params["sign_type"])
+
+	res, err := client.Verify(params)
+	asserts.NoError(err)
+	asserts.True(res.VerifyStatus)
+}
+
+func TestKeySetRotation(t *testing.T) {
+	asserts := assert.New(t)
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	content := "money=1.00&trade_no=T1"
+	oldSign, _ := RSASignWithKey(content, oldKey)
+	newSign, _ := RSASignWithKey(content, newKey)
+
+	now := time.Unix(1700000000, 0)
+	keySet := NewKeySet(PlatformKey{ID: "old", Key: &oldKey.PublicKey, NotAfter: now.Add(time.Hour)})
+	keySet.now = func() time.Time { return now }
+
+	verified, err := keySet.Verify(content, oldSign)
+	asserts.NoError(err)
+	asserts.True(verified)
+	verified, _ = keySet.Verify(content, newSign)
+	asserts.False(verified)
+
+	// 轮换期间新旧公钥同时有效
+	asserts.NoError(keySet.Reload(func() ([]PlatformKey, error) {
+		return append(keySet.Keys(), PlatformKey{ID: "new", Key: &newKey.PublicKey, NotBefore: now}), nil
+	}))
+	verified, _ = keySet.Verify(content, newSign)
+	asserts.True(verified)
+	verified, _ = keySet.Verify(content, oldSign)
+	asserts.True(verified)
+
+	// 旧公钥过期
+	now = now.Add(2 * time.Hour)
+	verified, _ = keySet.Verify(content, oldSign)
+	asserts.False(verified)
+	asserts.Len(keySet.Active(), 1)
+
+	keySet.Store(nil)
+	_, err = keySet.Verify(content, newSign)
+	asserts.ErrorIs(err, ErrNoActiveKey)
+
+	// 零值的KeySet可直接使用
+	var zero KeySet
+	asserts.Empty(zero.Active())
+	zero.Store([]PlatformKey{{ID: "new", Key: &newKey.PublicKey}})
+	verified, err = zero.Verify(content, newSign)
+	asserts.NoError(err)
+	asserts.True(verified)
+}
@@ -0,0 +1,112 @@
+package epay
+
+import (
+	"crypto/rsa"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoActiveKey 没有处于有效期内的平台公钥
+var ErrNoActiveKey = errors.New("没有有效的平台公钥")
+
+// PlatformKey 平台公钥及其有效期
+type PlatformKey struct {
+	// 公钥标识
+	ID string
+	// 平台公钥
+	Key *rsa.PublicKey
+	// 生效时间，零值表示立即生效
+	NotBefore time.Time
+	// 失效时间，零值表示长期有效
+	NotAfter time.Time
+}
+
+// 判断公钥在指定时间是否有效
+func (k PlatformKey) activeAt(t time.Time) bool {
+	if !k.NotBefore.IsZero() && t.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && !t.Before(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+var _ Verifier = (*KeySet)(nil)
+
+// KeySet 平台公钥集合，用于平台轮换公钥期间同时信任新旧公钥
+// 作为 Config.Verifier 使用，签名由任一有效期内的公钥验证通过即可
+// 可在运行时通过 Store 或 Reload 替换公钥，无需重启
+type KeySet struct {
+	keys atomic.Pointer[[]PlatformKey]
+	now  func() time.Time // 为空时使用time.Now，零值的KeySet可直接使用
+}
+
+// NewKeySet 创建平台公钥集合
+func NewKeySet(keys ...PlatformKey) *KeySet {
+	s := &KeySet{now: time.Now}
+	s.Store(keys)
+	return s
+}
+
+// Store 替换全部公钥，并发安全
+func (s *KeySet) Store(keys []PlatformKey) {
+	copied := make([]PlatformKey, len(keys))
+	copy(copied, keys)
+	s.keys.Store(&copied)
+}
+
+// Reload 通过loader重新加载公钥，加载失败时保留原有公钥
+func (s *KeySet) Reload(loader func() ([]PlatformKey, error)) error {
+	keys, err := loader()
+	if err != nil {
+		return err
+	}
+	s.Store(keys)
+	return nil
+}
+
+// Keys 返回全部公钥
+func (s *KeySet) Keys() []PlatformKey {
+	keys := s.keys.Load()
+	if keys == nil {
+		return nil
+	}
+	copied := make([]PlatformKey, len(*keys))
+	copy(copied, *keys)
+	return copied
+}
+
+// Active 返回当前有效期内的公钥
+func (s *KeySet) Active() []PlatformKey {
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+	var active []PlatformKey
+	for _, k := range s.Keys() {
+		if k.Key != nil && k.activeAt(now) {
+			active = append(active, k)
+		}
+	}
+	return active
+}
+
+// Verify 使用有效期内的公钥依次验签，任一通过即返回true
+func (s *KeySet) Verify(content, sign string) (bool, error) {
+	active := s.Active()
+	if len(active) == 0 {
+		return false, ErrNoActiveKey
+	}
+	for _, k := range active {
+		verified, err := RSAVerifyWithKey(content, sign, k.Key)
+		if err != nil {
+			return false, err
+		}
+		if verified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,135 @@
+package epay
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// 商户信息原始响应
+// 平台部分字段可能以字符串形式返回数字，统一使用json.Number接收
+type merchantInfoResp struct {
+	Code    int         `json:"code"`
+	Message string      `json:"msg"`
+	PID     json.Number `json:"pid"`
+	Money   Amount      `json:"money"`
+
+	// V1字段
+	Active   json.Number `json:"active"`
+	Type     json.Number `json:"type"`
+	Account  string      `json:"account"`
+	Username string      `json:"username"`
+
+	// V2字段
+	Status        json.Number `json:"status"`
+	PayStatus     json.Number `json:"pay_status"`
+	SettleStatus  json.Number `json:"settle_status"`
+	SettleType    json.Number `json:"settle_type"`
+	SettleAccount string      `json:"settle_account"`
+	SettleName    string      `json:"settle_name"`
+
+	OrderNum        json.Number `json:"order_num"`
+	OrderNumToday   json.Number `json:"order_num_today"`
+	OrderNumLastday json.Number `json:"order_num_lastday"`
+
+	// V1订单数量字段
+	Orders       json.Number `json:"orders"`
+	OrderToday   json.Number `json:"order_today"`
+	OrderLastday json.Number `json:"order_lastday"`
+
+	Timestamp string `json:"timestamp,omitempty"`
+	Sign      string `json:"sign,omitempty"`
+	SignType  string `json:"sign_type,omitempty"`
+}
+
+// 转换为统一的商户信息
+func (r *merchantInfoResp) toRes() *MerchantInfoRes {
+	res := &MerchantInfoRes{
+		Code:          r.Code,
+		Message:       r.Message,
+		PID:           r.PID.String(),
+		Money:         r.Money,
+		Status:        numberToInt(r.Status),
+		PayStatus:     numberToInt(r.PayStatus),
+		SettleStatus:  numberToInt(r.SettleStatus),
+		SettleType:    numberToInt(r.SettleType),
+		SettleAccount: r.SettleAccount,
+		SettleName:    r.SettleName,
+		Orders:        numberToInt(r.OrderNum),
+		OrderToday:    numberToInt(r.OrderNumToday),
+		OrderLastday:  numberToInt(r.OrderNumLastday),
+		Timestamp:     r.Timestamp,
+		Sign:          r.Sign,
+		SignType:      r.SignType,
+	}
+	// V1字段名不同
+	if r.Active != "" {
+		res.Status = numberToInt(r.Active)
+	}
+	if r.Type != "" {
+		res.SettleType = numberToInt(r.Type)
+	}
+	if r.Account != "" {
+		res.SettleAccount = r.Account
+	}
+	if r.Username != "" {
+		res.SettleName = r.Username
+	}
+	if r.Orders != "" {
+		res.Orders = numberToInt(r.Orders)
+	}
+	if r.OrderToday != "" {
+		res.OrderToday = numberToInt(r.OrderToday)
+	}
+	if r.OrderLastday != "" {
+		res.OrderLastday = numberToInt(r.OrderLastday)
+	}
+	return res
+}
+
+// 结算记录原始响应
+type settlementsResp struct {
+	Code    int              `json:"code"`
+	Message string           `json:"msg"`
+	Data    []settlementResp `json:"data"`
+}
+
+type settlementResp struct {
+	ID        json.Number `json:"id"`
+	Type      json.Number `json:"type"`
+	Account   string      `json:"account"`
+	Username  string      `json:"username"`
+	Money     Amount      `json:"money"`
+	RealMoney Amount      `json:"realmoney"`
+	Status    json.Number `json:"status"`
+	AddTime   string      `json:"addtime"`
+	EndTime   string      `json:"endtime"`
+}
+
+// 转换为结算记录列表
+func (r *settlementsResp) toRes() *ListSettlementsRes {
+	res := &ListSettlementsRes{
+		Code:    r.Code,
+		Message: r.Message,
+		Data:    make([]Settlement, 0, len(r.Data)),
+	}
+	for _, item := range r.Data {
+		res.Data = append(res.Data, Settlement{
+			ID:        numberToInt(item.ID),
+			Type:      numberToInt(item.Type),
+			Account:   item.Account,
+			Username:  item.Username,
+			Money:     item.Money,
+			RealMoney: item.RealMoney,
+			Fee:       item.Money - item.RealMoney,
+			Status:    numberToInt(item.Status),
+			AddTime:   item.AddTime,
+			EndTime:   item.EndTime,
+		})
+	}
+	return res
+}
+
+func numberToInt(n json.Number) int {
+	i, _ := strconv.Atoi(n.String())
+	return i
+}
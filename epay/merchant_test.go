@@ -0,0 +1,72 @@
+package epay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerchantInfoToRes(t *testing.T) {
+	asserts := assert.New(t)
+
+	// V1 act=query 响应
+	var v1 merchantInfoResp
+	asserts.NoError(json.Unmarshal([]byte(`{"code":1,"pid":1000,"key":"KEY","active":"1","money":"12.30","type":"1",
+		"account":"a@example.com","username":"张三","orders":"30","order_today":"3","order_lastday":5}`), &v1))
+	res := v1.toRes()
+	asserts.Equal("1000", res.PID)
+	asserts.Equal(1, res.Status)
+	asserts.Equal(1, res.SettleType)
+	asserts.Equal("a@example.com", res.SettleAccount)
+	asserts.Equal("张三", res.SettleName)
+	asserts.Equal(MustParseAmount("12.30"), res.Money)
+	asserts.Equal(30, res.Orders)
+	asserts.Equal(3, res.OrderToday)
+	asserts.Equal(5, res.OrderLastday)
+
+	// V2 /api/merchant/info 响应
+	var v2 merchantInfoResp
+	asserts.NoError(json.Unmarshal([]byte(`{"code":0,"pid":1000,"status":1,"pay_status":1,"settle_status":0,"money":"8.00",
+		"settle_type":2,"settle_account":"wx","settle_name":"李四","order_num":40,"order_num_today":4,"order_num_lastday":6}`), &v2))
+	res = v2.toRes()
+	asserts.Equal(1, res.Status)
+	asserts.Equal(1, res.PayStatus)
+	asserts.Equal(2, res.SettleType)
+	asserts.Equal("李四", res.SettleName)
+	asserts.Equal(40, res.Orders)
+	asserts.Equal(4, res.OrderToday)
+	asserts.Equal(6, res.OrderLastday)
+}
+
+func TestSettlementsToRes(t *testing.T) {
+	asserts := assert.New(t)
+	var resp settlementsResp
+	asserts.NoError(json.Unmarshal([]byte(`{"code":1,"msg":"查询结算记录成功！","data":[
+		{"id":"1","type":"1","account":"a@example.com","username":"张三","money":"100.00","realmoney":"99.40","status":"1","addtime":"2024-01-01 00:00:00","endtime":"2024-01-02 00:00:00"},
+		{"id":2,"type":2,"money":50,"realmoney":"50.00","status":0}
+	]}`), &resp))
+
+	res := resp.toRes()
+	asserts.Equal(1, res.Code)
+	asserts.Len(res.Data, 2)
+	asserts.Equal(Settlement{
+		ID:        1,
+		Type:      1,
+		Account:   "a@example.com",
+		Username:  "张三",
+		Money:     MustParseAmount("100.00"),
+		RealMoney: MustParseAmount("99.40"),
+		Fee:       MustParseAmount("0.60"),
+		Status:    1,
+		AddTime:   "2024-01-01 00:00:00",
+		EndTime:   "2024-01-02 00:00:00",
+	}, res.Data[0])
+	asserts.Equal(2, res.Data[1].ID)
+	asserts.Equal(Amount(0), res.Data[1].Fee)
+	asserts.Equal(0, res.Data[1].Status)
+
+	// 无结算记录时返回空列表
+	resp = settlementsResp{Code: 1}
+	asserts.NotNil(resp.toRes().Data)
+}
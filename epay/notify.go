@@ -0,0 +1,74 @@
+package epay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// 平台要求的回调应答内容
+const (
+	NotifyAckSuccess = "success"
+	NotifyAckFail    = "fail"
+)
+
+// NotifyFunc 支付成功回调的业务处理函数，返回错误时应答fail以便平台重新通知
+type NotifyFunc func(ctx context.Context, res *VerifyRes) error
+
+// NotifyHandler 异步通知处理器
+// 解析GET查询参数及POST表单参数并验签，仅在验签通过且交易状态为TRADE_SUCCESS时调用fn
+func NotifyHandler(client *Client, fn NotifyFunc) http.Handler {
+	return notifyHandler(func(params map[string]string) (*Client, *VerifyRes, error) {
+		verifyRes, err := client.Verify(params)
+		return client, verifyRes, err
+	}, fn)
+}
+
+// 通知处理流程，verify返回验签使用的客户端，用于业务失败时清除防重放记录
+func notifyHandler(verify func(params map[string]string) (*Client, *VerifyRes, error), fn NotifyFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := requestParams(r)
+		if err != nil {
+			writeNotifyAck(w, NotifyAckFail)
+			return
+		}
+
+		client, verifyRes, err := verify(params)
+		if errors.Is(err, ErrNotifyReplayed) {
+			// 已处理过的通知直接应答成功，避免平台重复通知
+			writeNotifyAck(w, NotifyAckSuccess)
+			return
+		}
+		if err != nil || !verifyRes.VerifyStatus {
+			writeNotifyAck(w, NotifyAckFail)
+			return
+		}
+
+		if verifyRes.TradeStatus == StatusTradeSuccess {
+			if err := fn(r.Context(), verifyRes); err != nil {
+				// 业务处理失败，清除防重放记录以便平台重新通知
+				client.ForgetNotify(params)
+				writeNotifyAck(w, NotifyAckFail)
+				return
+			}
+		}
+		writeNotifyAck(w, NotifyAckSuccess)
+	})
+}
+
+// 合并GET查询参数与POST表单参数
+func requestParams(r *http.Request) (map[string]string, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	params := make(map[string]string, len(r.Form))
+	for k := range r.Form {
+		params[k] = r.Form.Get(k)
+	}
+	return params, nil
+}
+
+func writeNotifyAck(w http.ResponseWriter, ack string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(ack))
+}
@@ -0,0 +1,106 @@
+package epay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyHandler(t *testing.T) {
+	asserts := assert.New(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost")
+
+	var handled []string
+	failNext := false
+	handler := NotifyHandler(client, func(ctx context.Context, res *VerifyRes) error {
+		if failNext {
+			return errors.New("处理失败")
+		}
+		handled = append(handled, res.OutTradeNo)
+		return nil
+	})
+
+	params := GenerateParams(map[string]string{
+		"pid":          "1000",
+		"trade_no":     "T1",
+		"out_trade_no": "O1",
+		"money":        "1.00",
+		"trade_status": StatusTradeSuccess,
+	}, "KEY", SignTypeMD5)
+	form := url.Values{}
+	for k, v := range params {
+		form.Set(k, v)
+	}
+
+	// GET查询参数
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notify?"+form.Encode(), nil))
+	asserts.Equal(NotifyAckSuccess, rec.Body.String())
+
+	// POST表单参数
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(rec, req)
+	asserts.Equal(NotifyAckSuccess, rec.Body.String())
+	asserts.Equal([]string{"O1", "O1"}, handled)
+
+	// 业务处理失败
+	failNext = true
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notify?"+form.Encode(), nil))
+	asserts.Equal(NotifyAckFail, rec.Body.String())
+
+	// 签名错误不调用业务处理
+	failNext = false
+	form.Set("money", "100.00")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notify?"+form.Encode(), nil))
+	asserts.Equal(NotifyAckFail, rec.Body.String())
+	asserts.Len(handled, 2)
+}
+
+func TestNotifyHandlerCheckExpected(t *testing.T) {
+	asserts := assert.New(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost", WithNotifyStore(NewMemoryNotifyStore(0), 0))
+
+	var fulfilled []string
+	handler := NotifyHandler(client, func(ctx context.Context, res *VerifyRes) error {
+		// 回调中比较预期订单，不能再次验签记录防重放
+		expected := OrderExpectation{OutTradeNo: "O1", Money: MustParseAmount("1.00")}
+		if mismatches := res.CheckExpected(client.Config.PartnerID, expected); len(mismatches) > 0 {
+			return &ExpectationError{Mismatches: mismatches}
+		}
+		fulfilled = append(fulfilled, res.OutTradeNo)
+		return nil
+	})
+
+	notify := func(money string) string {
+		params := GenerateParams(map[string]string{
+			"pid":          "1000",
+			"trade_no":     "T" + money,
+			"out_trade_no": "O1",
+			"money":        money,
+			"trade_status": StatusTradeSuccess,
+		}, "KEY", SignTypeMD5)
+		form := url.Values{}
+		for k, v := range params {
+			form.Set(k, v)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	asserts.Equal(NotifyAckSuccess, notify("1.00"))
+	asserts.Equal(NotifyAckFail, notify("0.01"))
+	asserts.Equal([]string{"O1"}, fulfilled)
+}
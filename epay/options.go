@@ -0,0 +1,106 @@
+package epay
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClientOption 客户端配置项
+type ClientOption func(*Client)
+
+// WithHTTPClient 使用自定义的http.Client发送请求
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout 设置请求超时时间
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		hc := c.cloneHTTPClient()
+		hc.Timeout = timeout
+		c.httpClient = hc
+	}
+}
+
+// WithTransport 设置底层传输层，可用于配置代理、TLS证书及连接池
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		hc := c.cloneHTTPClient()
+		hc.Transport = transport
+		c.httpClient = hc
+	}
+}
+
+// WithUserAgent 设置请求的User-Agent
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithoutResponseVerify 关闭V2接口响应签名校验
+// 默认会使用平台公钥校验所有V2接口的响应，仅建议在调试时关闭
+func WithoutResponseVerify() ClientOption {
+	return func(c *Client) {
+		c.skipResponseVerify = true
+	}
+}
+
+// WithTimestampSkew 校验回调通知的timestamp参数，与本地时间相差超过skew时拒绝
+func WithTimestampSkew(skew time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timestampSkew = skew
+	}
+}
+
+// WithNotifyStore 开启回调防重放，窗口期内相同的trade_no与sign只接受一次
+func WithNotifyStore(store NotifyStore, window time.Duration) ClientOption {
+	return func(c *Client) {
+		c.notifyStore = store
+		c.notifyWindow = window
+	}
+}
+
+// WithBackupURLs 设置备用网关地址，主地址不可用时按顺序切换
+// 仅API创建订单与查询订单会自动切换，可通过 Client.Endpoints 查看各地址状态
+// API创建订单仅在连接失败时切换，设置 ApiCreateOrderArgs.Idempotent 后超时及5xx响应也会切换
+func WithBackupURLs(baseUrls ...string) ClientOption {
+	return func(c *Client) {
+		c.backupUrls = append(c.backupUrls, baseUrls...)
+	}
+}
+
+// WithCircuitBreaker 设置网关地址熔断器，连续失败threshold次后暂停使用cooldown时长
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *Client) {
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
+
+// WithRetryPolicy 设置请求重试策略，查询类接口网络错误或5xx响应时自动重试
+// API创建订单仅在 ApiCreateOrderArgs.Idempotent 为true时重试
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// 复制当前的http.Client，避免修改调用方传入的实例
+func (c *Client) cloneHTTPClient() *http.Client {
+	if c.httpClient == nil {
+		return &http.Client{}
+	}
+	hc := *c.httpClient
+	return &hc
+}
+
+// 获取发送请求使用的http.Client
+func (c *Client) getHTTPClient() *http.Client {
+	if c.httpClient == nil {
+		return http.DefaultClient
+	}
+	return c.httpClient
+}
@@ -1,8 +1,19 @@
 package epay
 
+import "context"
+
 // 创建订单
 func (c *Client) CreateOrder(args *CreateOrderArgs) (string, map[string]string, error) {
-	if c.Config.PublicKey != "" {
+	return c.CreateOrderWithContext(context.Background(), args)
+}
+
+// CreateOrderWithContext 同CreateOrder，使用 VersionAuto 时通过ctx控制接口版本探测的超时与取消
+func (c *Client) CreateOrderWithContext(ctx context.Context, args *CreateOrderArgs) (string, map[string]string, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if version == VersionV2 {
 		return c.V2CreateOrder(args)
 	}
 	return c.V1CreateOrder(args)
@@ -10,16 +21,136 @@ func (c *Client) CreateOrder(args *CreateOrderArgs) (string, map[string]string,
 
 // API创建订单
 func (c *Client) ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error) {
-	if c.Config.PublicKey != "" {
-		return c.V2ApiCreateOrder(args)
+	return c.ApiCreateOrderWithContext(context.Background(), args)
+}
+
+// ApiCreateOrderWithContext 同ApiCreateOrder，通过ctx控制请求的超时与取消
+func (c *Client) ApiCreateOrderWithContext(ctx context.Context, args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return c.V1ApiCreateOrder(args)
+	if version == VersionV2 {
+		return c.V2ApiCreateOrderWithContext(ctx, args)
+	}
+	return c.V1ApiCreateOrderWithContext(ctx, args)
 }
 
 // 单个订单查询
 func (c *Client) QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
-	if c.Config.PublicKey != "" {
-		return c.V2QueryOrder(tradeNo, outTradeNo)
+	return c.QueryOrderWithContext(context.Background(), tradeNo, outTradeNo)
+}
+
+// QueryOrderWithContext 同QueryOrder，通过ctx控制请求的超时与取消
+func (c *Client) QueryOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return c.V2QueryOrderWithContext(ctx, tradeNo, outTradeNo)
+	}
+	return c.V1QueryOrderWithContext(ctx, tradeNo, outTradeNo)
+}
+
+// 订单退款
+func (c *Client) Refund(args *RefundArgs) (*RefundRes, error) {
+	return c.RefundWithContext(context.Background(), args)
+}
+
+// RefundWithContext 同Refund，通过ctx控制请求的超时与取消
+func (c *Client) RefundWithContext(ctx context.Context, args *RefundArgs) (*RefundRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return c.V2RefundWithContext(ctx, args)
+	}
+	return c.V1RefundWithContext(ctx, args)
+}
+
+// 查询退款，仅V2接口支持
+func (c *Client) QueryRefund(refundNo, outRefundNo string) (*RefundQueryRes, error) {
+	return c.QueryRefundWithContext(context.Background(), refundNo, outRefundNo)
+}
+
+// QueryRefundWithContext 同QueryRefund，通过ctx控制请求的超时与取消
+func (c *Client) QueryRefundWithContext(ctx context.Context, refundNo, outRefundNo string) (*RefundQueryRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return c.V2QueryRefundWithContext(ctx, refundNo, outRefundNo)
+	}
+	return nil, ErrUnsupported
+}
+
+// 关闭未支付订单，仅V2接口支持
+func (c *Client) CloseOrder(tradeNo, outTradeNo string) (*CloseOrderRes, error) {
+	return c.CloseOrderWithContext(context.Background(), tradeNo, outTradeNo)
+}
+
+// CloseOrderWithContext 同CloseOrder，通过ctx控制请求的超时与取消
+func (c *Client) CloseOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*CloseOrderRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return c.V2CloseOrderWithContext(ctx, tradeNo, outTradeNo)
+	}
+	return nil, ErrUnsupported
+}
+
+// 查询商户信息
+func (c *Client) MerchantInfo() (*MerchantInfoRes, error) {
+	return c.MerchantInfoWithContext(context.Background())
+}
+
+// MerchantInfoWithContext 同MerchantInfo，通过ctx控制请求的超时与取消
+func (c *Client) MerchantInfoWithContext(ctx context.Context) (*MerchantInfoRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return c.V2MerchantInfoWithContext(ctx)
+	}
+	return c.V1MerchantInfoWithContext(ctx)
+}
+
+// 分页查询订单列表
+func (c *Client) ListOrders(args *ListOrdersArgs) (*ListOrdersRes, error) {
+	return c.ListOrdersWithContext(context.Background(), args)
+}
+
+// ListOrdersWithContext 同ListOrders，通过ctx控制请求的超时与取消
+func (c *Client) ListOrdersWithContext(ctx context.Context, args *ListOrdersArgs) (*ListOrdersRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return c.V2ListOrdersWithContext(ctx, args)
+	}
+	return c.V1ListOrdersWithContext(ctx, args)
+}
+
+// 查询结算记录，仅V1接口支持
+func (c *Client) ListSettlements() (*ListSettlementsRes, error) {
+	return c.ListSettlementsWithContext(context.Background())
+}
+
+// ListSettlementsWithContext 同ListSettlements，通过ctx控制请求的超时与取消
+func (c *Client) ListSettlementsWithContext(ctx context.Context) (*ListSettlementsRes, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == VersionV2 {
+		return nil, ErrUnsupported
 	}
-	return c.V1QueryOrder(tradeNo, outTradeNo)
+	return c.V1ListSettlementsWithContext(ctx)
 }
@@ -1,20 +1,22 @@
 package epay
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"net/http"
 	"net/url"
 	"path"
-
-	"github.com/samber/lo"
+	"strconv"
 )
 
 const (
 	V1CreateUrl    = "/submit.php" // v1 跳转支付
 	V1ApiCreateUrl = "/mapi.php"   // v1 API支付
 	V1QueryUrl     = "/api.php"    // v1 查询订单
+	V1RefundUrl    = "/api.php"    // v1 订单退款（act=refund）
+	V1MerchantUrl  = "/api.php"    // v1 查询商户信息（act=query）
+	V1OrdersUrl    = "/api.php"    // v1 查询订单列表（act=orders）
+	V1SettleUrl    = "/api.php"    // v1 查询结算记录（act=settle）
 )
 
 // 创建订单
@@ -26,7 +28,7 @@ func (c *Client) V1CreateOrder(args *CreateOrderArgs) (string, map[string]string
 		"notify_url":   args.NotifyUrl.String(),
 		"return_url":   args.ReturnUrl.String(),
 		"name":         args.Name,
-		"money":        args.Money,
+		"money":        args.Money.String(),
 	}
 	if args.Param != "" {
 		requestParams["param"] = args.Param
@@ -38,11 +40,20 @@ func (c *Client) V1CreateOrder(args *CreateOrderArgs) (string, map[string]string
 	}
 	u.Path = path.Join(u.Path, V1CreateUrl)
 
-	return u.String(), GenerateParams(requestParams, c.Config.Key, SignTypeMD5), nil
+	signParams, err := c.signParams(requestParams, SignTypeMD5)
+	if err != nil {
+		return "", nil, err
+	}
+	return u.String(), signParams, nil
 }
 
 // API接口创建订单
 func (c *Client) V1ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error) {
+	return c.V1ApiCreateOrderWithContext(context.Background(), args)
+}
+
+// V1ApiCreateOrderWithContext 同V1ApiCreateOrder，通过ctx控制请求的超时与取消
+func (c *Client) V1ApiCreateOrderWithContext(ctx context.Context, args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error) {
 	// 构建请求参数
 	requestParams := map[string]string{
 		"pid":          c.Config.PartnerID,
@@ -50,7 +61,7 @@ func (c *Client) V1ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes,
 		"out_trade_no": args.OutTradeNo,
 		"notify_url":   args.NotifyURL.String(),
 		"name":         args.Name,
-		"money":        args.Money,
+		"money":        args.Money.String(),
 		"clientip":     args.ClientIP,
 	}
 
@@ -66,81 +77,251 @@ func (c *Client) V1ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes,
 	}
 
 	// 生成签名
-	signParams := GenerateParams(requestParams, c.Config.Key, SignTypeMD5)
+	signParams, err := c.signParams(requestParams, SignTypeMD5)
+	if err != nil {
+		return nil, err
+	}
 
-	// 构建API接口URL
-	apiUrl, err := url.Parse(c.BaseUrl.String())
+	var result ApiCreateOrderRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, args.Idempotent, func() error {
+		// 发送POST请求，网关不可用时切换到备用地址
+		body, err := c.postFormFailover(ctx, V1ApiCreateUrl, signParams, args.Idempotent)
+		if err != nil {
+			return err
+		}
+		result = ApiCreateOrderRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		return checkCode(VersionV1, result.Code, result.Message, body)
+	})
 	if err != nil {
 		return nil, err
 	}
-	apiUrl.Path = path.Join(apiUrl.Path, V1ApiCreateUrl)
 
-	// 发送POST请求
-	resp, err := http.PostForm(apiUrl.String(), url.Values(lo.MapValues(signParams, func(v string, _ string) []string {
-		return []string{v}
-	})))
+	return &result, nil
+}
+
+// 查询单个订单
+func (c *Client) V1QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+	return c.V1QueryOrderWithContext(context.Background(), tradeNo, outTradeNo)
+}
+
+// V1QueryOrderWithContext 同V1QueryOrder，通过ctx控制请求的超时与取消
+func (c *Client) V1QueryOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+	// 设置查询参数
+	query := url.Values{}
+	query.Add("act", "order")
+	query.Add("pid", c.Config.PartnerID)
+	query.Add("key", c.Config.Key) // 使用商户密钥
+
+	// 至少需要传入一个订单号
+	if tradeNo != "" {
+		query.Add("trade_no", tradeNo)
+	} else if outTradeNo != "" {
+		query.Add("out_trade_no", outTradeNo)
+	} else {
+		return nil, errors.New("必须提供系统订单号或商户订单号")
+	}
+
+	var result ApiOrderQueryRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err := c.withRetry(ctx, true, func() error {
+		// 发送GET请求，网关不可用时切换到备用地址
+		body, err := c.getFailover(ctx, V1QueryUrl, query)
+		if err != nil {
+			return err
+		}
+		result = ApiOrderQueryRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		return checkCode(VersionV1, result.Code, result.Message, body)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// 解析JSON响应
-	body, err := io.ReadAll(resp.Body)
+	return &result, nil
+}
+
+// 订单退款
+func (c *Client) V1Refund(args *RefundArgs) (*RefundRes, error) {
+	return c.V1RefundWithContext(context.Background(), args)
+}
+
+// V1RefundWithContext 同V1Refund，通过ctx控制请求的超时与取消
+func (c *Client) V1RefundWithContext(ctx context.Context, args *RefundArgs) (*RefundRes, error) {
+	if args.Money <= 0 {
+		return nil, errors.New("必须提供退款金额")
+	}
+
+	// 构建请求参数
+	requestParams := map[string]string{
+		"pid":   c.Config.PartnerID,
+		"key":   c.Config.Key, // 使用商户密钥
+		"money": args.Money.String(),
+	}
+
+	// 至少需要传入一个订单号
+	if args.TradeNo != "" {
+		requestParams["trade_no"] = args.TradeNo
+	} else if args.OutTradeNo != "" {
+		requestParams["out_trade_no"] = args.OutTradeNo
+	} else {
+		return nil, errors.New("必须提供系统订单号或商户订单号")
+	}
+
+	// 构建API接口URL
+	refundUrl, err := c.apiUrl(V1RefundUrl)
 	if err != nil {
 		return nil, err
 	}
+	query := refundUrl.Query()
+	query.Add("act", "refund")
+	refundUrl.RawQuery = query.Encode()
 
-	var result ApiCreateOrderRes
+	body, err := c.postForm(ctx, refundUrl, requestParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var result RefundRes
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
+	// 检查响应状态码
+	if err := checkCode(VersionV1, result.Code, result.Message, body); err != nil {
+		return nil, err
+	}
+
 	return &result, nil
 }
 
-// 查询单个订单
-func (c *Client) V1QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+// 查询商户信息
+func (c *Client) V1MerchantInfo() (*MerchantInfoRes, error) {
+	return c.V1MerchantInfoWithContext(context.Background())
+}
+
+// V1MerchantInfoWithContext 同V1MerchantInfo，通过ctx控制请求的超时与取消
+func (c *Client) V1MerchantInfoWithContext(ctx context.Context) (*MerchantInfoRes, error) {
 	// 构建请求参数
-	queryUrl, err := url.Parse(c.BaseUrl.String())
+	queryUrl, err := c.apiUrl(V1MerchantUrl)
 	if err != nil {
 		return nil, err
 	}
-	queryUrl.Path = path.Join(queryUrl.Path, V1QueryUrl)
 
 	// 设置查询参数
 	query := queryUrl.Query()
-	query.Add("act", "order")
+	query.Add("act", "query")
 	query.Add("pid", c.Config.PartnerID)
 	query.Add("key", c.Config.Key) // 使用商户密钥
+	queryUrl.RawQuery = query.Encode()
 
-	// 至少需要传入一个订单号
-	if tradeNo != "" {
-		query.Add("trade_no", tradeNo)
-	} else if outTradeNo != "" {
-		query.Add("out_trade_no", outTradeNo)
-	} else {
-		return nil, errors.New("必须提供系统订单号或商户订单号")
+	var result merchantInfoResp
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		body, err := c.get(ctx, queryUrl)
+		if err != nil {
+			return err
+		}
+		result = merchantInfoResp{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		return checkCode(VersionV1, result.Code, result.Message, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.toRes(), nil
+}
+
+// 分页查询订单列表
+func (c *Client) V1ListOrders(args *ListOrdersArgs) (*ListOrdersRes, error) {
+	return c.V1ListOrdersWithContext(context.Background(), args)
+}
+
+// V1ListOrdersWithContext 同V1ListOrders，通过ctx控制请求的超时与取消
+func (c *Client) V1ListOrdersWithContext(ctx context.Context, args *ListOrdersArgs) (*ListOrdersRes, error) {
+	// 构建请求参数
+	queryUrl, err := c.apiUrl(V1OrdersUrl)
+	if err != nil {
+		return nil, err
 	}
 
+	// 设置查询参数
+	query := queryUrl.Query()
+	query.Add("act", "orders")
+	query.Add("pid", c.Config.PartnerID)
+	query.Add("key", c.Config.Key) // 使用商户密钥
+	if args.Limit > 0 {
+		query.Add("limit", strconv.Itoa(args.Limit))
+	}
+	if args.Offset > 0 {
+		query.Add("offset", strconv.Itoa(args.Offset))
+	}
 	queryUrl.RawQuery = query.Encode()
 
-	// 发送GET请求
-	resp, err := http.Get(queryUrl.String())
+	var result ListOrdersRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		body, err := c.get(ctx, queryUrl)
+		if err != nil {
+			return err
+		}
+		result = ListOrdersRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		return checkCode(VersionV1, result.Code, result.Message, body)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// 解析JSON响应
-	body, err := io.ReadAll(resp.Body)
+	return &result, nil
+}
+
+// 查询结算记录
+func (c *Client) V1ListSettlements() (*ListSettlementsRes, error) {
+	return c.V1ListSettlementsWithContext(context.Background())
+}
+
+// V1ListSettlementsWithContext 同V1ListSettlements，通过ctx控制请求的超时与取消
+func (c *Client) V1ListSettlementsWithContext(ctx context.Context) (*ListSettlementsRes, error) {
+	// 构建请求参数
+	queryUrl, err := c.apiUrl(V1SettleUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	var result ApiOrderQueryRes
-	if err := json.Unmarshal(body, &result); err != nil {
+	// 设置查询参数
+	query := queryUrl.Query()
+	query.Add("act", "settle")
+	query.Add("pid", c.Config.PartnerID)
+	query.Add("key", c.Config.Key) // 使用商户密钥
+	queryUrl.RawQuery = query.Encode()
+
+	var result settlementsResp
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		body, err := c.get(ctx, queryUrl)
+		if err != nil {
+			return err
+		}
+		result = settlementsResp{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		return checkCode(VersionV1, result.Code, result.Message, body)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &result, nil
+	return result.toRes(), nil
 }
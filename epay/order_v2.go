@@ -1,23 +1,25 @@
 package epay
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"path"
 	"strconv"
 	"time"
-
-	"github.com/samber/lo"
 )
 
 const (
-	V2CreateUrl    = "/api/pay/submit" // v2 跳转支付
-	V2ApiCreateUrl = "/api/pay/create" // v2 API支付
-	V2QueryUrl     = "/api/pay/query"  // v2 查询订单
+	V2CreateUrl      = "/api/pay/submit"      // v2 跳转支付
+	V2ApiCreateUrl   = "/api/pay/create"      // v2 API支付
+	V2QueryUrl       = "/api/pay/query"       // v2 查询订单
+	V2RefundUrl      = "/api/pay/refund"      // v2 订单退款
+	V2RefundQueryUrl = "/api/pay/refundquery" // v2 查询退款
+	V2CloseUrl       = "/api/pay/close"       // v2 关闭订单
+	V2MerchantUrl    = "/api/merchant/info"   // v2 查询商户信息
+	V2OrdersUrl      = "/api/merchant/orders" // v2 查询订单列表
 )
 
 // 创建订单
@@ -29,7 +31,7 @@ func (c *Client) V2CreateOrder(args *CreateOrderArgs) (string, map[string]string
 		"notify_url":   args.NotifyUrl.String(),
 		"return_url":   args.ReturnUrl.String(),
 		"name":         args.Name,
-		"money":        args.Money,
+		"money":        args.Money.String(),
 		"timestamp":    strconv.FormatInt(time.Now().Unix(), 10),
 	}
 
@@ -44,11 +46,20 @@ func (c *Client) V2CreateOrder(args *CreateOrderArgs) (string, map[string]string
 	}
 	u.Path = path.Join(u.Path, V2CreateUrl)
 
-	return u.String(), GenerateParams(requestParams, c.Config.Key, SignTypeRSA), nil
+	signParams, err := c.signParams(requestParams, c.v2SignType())
+	if err != nil {
+		return "", nil, err
+	}
+	return u.String(), signParams, nil
 }
 
 // API创建订单
 func (c *Client) V2ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error) {
+	return c.V2ApiCreateOrderWithContext(context.Background(), args)
+}
+
+// V2ApiCreateOrderWithContext 同V2ApiCreateOrder，通过ctx控制请求的超时与取消
+func (c *Client) V2ApiCreateOrderWithContext(ctx context.Context, args *ApiCreateOrderArgs) (*ApiCreateOrderRes, error) {
 	// 构建请求参数
 	requestParams := map[string]string{
 		"pid":          c.Config.PartnerID,
@@ -58,7 +69,7 @@ func (c *Client) V2ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes,
 		"notify_url":   args.NotifyURL.String(),
 		"return_url":   args.ReturnURL.String(),
 		"name":         args.Name,
-		"money":        args.Money,
+		"money":        args.Money.String(),
 		"clientip":     args.ClientIP,
 		"timestamp":    fmt.Sprintf("%d", time.Now().Unix()),
 	}
@@ -81,46 +92,96 @@ func (c *Client) V2ApiCreateOrder(args *ApiCreateOrderArgs) (*ApiCreateOrderRes,
 	}
 
 	// 生成签名
-	signParams := GenerateParams(requestParams, c.Config.Key, SignTypeRSA)
-
-	// 构建API接口URL
-	apiUrl, err := url.Parse(c.BaseUrl.String())
+	signParams, err := c.signParams(requestParams, c.v2SignType())
 	if err != nil {
 		return nil, err
 	}
-	apiUrl.Path = path.Join(apiUrl.Path, V2ApiCreateUrl)
 
-	// 发送POST请求
-	resp, err := http.PostForm(apiUrl.String(), url.Values(lo.MapValues(signParams, func(v string, _ string) []string {
-		return []string{v}
-	})))
+	var result ApiCreateOrderRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, args.Idempotent, func() error {
+		// 发送POST请求，网关不可用时切换到备用地址
+		body, err := c.postFormFailover(ctx, V2ApiCreateUrl, signParams, args.Idempotent)
+		if err != nil {
+			return err
+		}
+		result = ApiCreateOrderRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+			return err
+		}
+		// 校验平台签名
+		return c.verifyResponse(body)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// 解析JSON响应
-	body, err := io.ReadAll(resp.Body)
+	return &result, nil
+}
+
+// 查询单个订单
+func (c *Client) V2QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+	return c.V2QueryOrderWithContext(context.Background(), tradeNo, outTradeNo)
+}
+
+// V2QueryOrderWithContext 同V2QueryOrder，通过ctx控制请求的超时与取消
+func (c *Client) V2QueryOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+	// 构建请求参数
+	requestParams := map[string]string{
+		"pid":       c.Config.PartnerID,
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	// 至少需要传入一个订单号
+	if tradeNo != "" {
+		requestParams["trade_no"] = tradeNo
+	} else if outTradeNo != "" {
+		requestParams["out_trade_no"] = outTradeNo
+	} else {
+		return nil, errors.New("必须提供系统订单号或商户订单号")
+	}
+
+	// 生成签名
+	signParams, err := c.signParams(requestParams, c.v2SignType())
 	if err != nil {
 		return nil, err
 	}
 
-	var result ApiCreateOrderRes
-	if err := json.Unmarshal(body, &result); err != nil {
+	var result ApiOrderQueryRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		// 发送POST请求，网关不可用时切换到备用地址
+		body, err := c.postFormFailover(ctx, V2QueryUrl, signParams, true)
+		if err != nil {
+			return err
+		}
+		result = ApiOrderQueryRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+			return err
+		}
+		// 校验平台签名
+		return c.verifyResponse(body)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// if result.Code != 0 {
-	// 	// 记录响应的完整内容用于调试
-	// 	responseBody := string(body)
-	// 	log.Printf("创建订单失败，服务器响应: %s", responseBody)
-	// }
-
 	return &result, nil
 }
 
-// 查询单个订单
-func (c *Client) V2QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, error) {
+// 关闭未支付订单
+func (c *Client) V2CloseOrder(tradeNo, outTradeNo string) (*CloseOrderRes, error) {
+	return c.V2CloseOrderWithContext(context.Background(), tradeNo, outTradeNo)
+}
+
+// V2CloseOrderWithContext 同V2CloseOrder，通过ctx控制请求的超时与取消
+func (c *Client) V2CloseOrderWithContext(ctx context.Context, tradeNo, outTradeNo string) (*CloseOrderRes, error) {
 	// 构建请求参数
 	requestParams := map[string]string{
 		"pid":       c.Config.PartnerID,
@@ -137,34 +198,265 @@ func (c *Client) V2QueryOrder(tradeNo, outTradeNo string) (*ApiOrderQueryRes, er
 	}
 
 	// 生成签名
-	signParams := GenerateParams(requestParams, c.Config.Key, SignTypeRSA)
+	signParams, err := c.signParams(requestParams, c.v2SignType())
+	if err != nil {
+		return nil, err
+	}
 
 	// 构建API接口URL
-	apiUrl, err := url.Parse(c.BaseUrl.String())
+	apiUrl, err := c.apiUrl(V2CloseUrl)
 	if err != nil {
 		return nil, err
 	}
-	apiUrl.Path = path.Join(apiUrl.Path, V2QueryUrl)
 
-	// 发送POST请求
-	resp, err := http.PostForm(apiUrl.String(), url.Values(lo.MapValues(signParams, func(v string, _ string) []string {
-		return []string{v}
-	})))
+	body, err := c.postForm(ctx, apiUrl, signParams)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// 解析JSON响应
-	body, err := io.ReadAll(resp.Body)
+	var result CloseOrderRes
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	// 检查响应状态码
+	if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+		return nil, err
+	}
+
+	// 校验平台签名
+	if err := c.verifyResponse(body); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// 查询退款
+func (c *Client) V2QueryRefund(refundNo, outRefundNo string) (*RefundQueryRes, error) {
+	return c.V2QueryRefundWithContext(context.Background(), refundNo, outRefundNo)
+}
+
+// V2QueryRefundWithContext 同V2QueryRefund，通过ctx控制请求的超时与取消
+func (c *Client) V2QueryRefundWithContext(ctx context.Context, refundNo, outRefundNo string) (*RefundQueryRes, error) {
+	// 构建请求参数
+	requestParams := map[string]string{
+		"pid":       c.Config.PartnerID,
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	// 至少需要传入一个退款单号
+	if refundNo != "" {
+		requestParams["refund_no"] = refundNo
+	} else if outRefundNo != "" {
+		requestParams["out_refund_no"] = outRefundNo
+	} else {
+		return nil, errors.New("必须提供平台退款单号或商户退款单号")
+	}
+
+	// 生成签名
+	signParams, err := c.signParams(requestParams, c.v2SignType())
 	if err != nil {
 		return nil, err
 	}
 
-	var result ApiOrderQueryRes
+	// 构建API接口URL
+	apiUrl, err := c.apiUrl(V2RefundQueryUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var result RefundQueryRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		body, err := c.postForm(ctx, apiUrl, signParams)
+		if err != nil {
+			return err
+		}
+		result = RefundQueryRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+			return err
+		}
+		// 校验平台签名
+		return c.verifyResponse(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// 订单退款
+func (c *Client) V2Refund(args *RefundArgs) (*RefundRes, error) {
+	return c.V2RefundWithContext(context.Background(), args)
+}
+
+// V2RefundWithContext 同V2Refund，通过ctx控制请求的超时与取消
+func (c *Client) V2RefundWithContext(ctx context.Context, args *RefundArgs) (*RefundRes, error) {
+	if args.Money <= 0 {
+		return nil, errors.New("必须提供退款金额")
+	}
+
+	// 构建请求参数
+	requestParams := map[string]string{
+		"pid":       c.Config.PartnerID,
+		"money":     args.Money.String(),
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	// 至少需要传入一个订单号
+	if args.TradeNo != "" {
+		requestParams["trade_no"] = args.TradeNo
+	} else if args.OutTradeNo != "" {
+		requestParams["out_trade_no"] = args.OutTradeNo
+	} else {
+		return nil, errors.New("必须提供系统订单号或商户订单号")
+	}
+
+	// 添加可选参数
+	if args.OutRefundNo != "" {
+		requestParams["out_refund_no"] = args.OutRefundNo
+	}
+
+	// 生成签名
+	signParams, err := c.signParams(requestParams, c.v2SignType())
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建API接口URL
+	apiUrl, err := c.apiUrl(V2RefundUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.postForm(ctx, apiUrl, signParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var result RefundRes
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
+	// 检查响应状态码
+	if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+		return nil, err
+	}
+
+	// 校验平台签名
+	if err := c.verifyResponse(body); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// 查询商户信息
+func (c *Client) V2MerchantInfo() (*MerchantInfoRes, error) {
+	return c.V2MerchantInfoWithContext(context.Background())
+}
+
+// V2MerchantInfoWithContext 同V2MerchantInfo，通过ctx控制请求的超时与取消
+func (c *Client) V2MerchantInfoWithContext(ctx context.Context) (*MerchantInfoRes, error) {
+	// 构建请求参数
+	requestParams := map[string]string{
+		"pid":       c.Config.PartnerID,
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	// 生成签名
+	signParams, err := c.signParams(requestParams, c.v2SignType())
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建API接口URL
+	apiUrl, err := c.apiUrl(V2MerchantUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var result merchantInfoResp
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		body, err := c.postForm(ctx, apiUrl, signParams)
+		if err != nil {
+			return err
+		}
+		result = merchantInfoResp{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+			return err
+		}
+		// 校验平台签名
+		return c.verifyResponse(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.toRes(), nil
+}
+
+// 分页查询订单列表
+func (c *Client) V2ListOrders(args *ListOrdersArgs) (*ListOrdersRes, error) {
+	return c.V2ListOrdersWithContext(context.Background(), args)
+}
+
+// V2ListOrdersWithContext 同V2ListOrders，通过ctx控制请求的超时与取消
+func (c *Client) V2ListOrdersWithContext(ctx context.Context, args *ListOrdersArgs) (*ListOrdersRes, error) {
+	// 构建请求参数
+	requestParams := map[string]string{
+		"pid":       c.Config.PartnerID,
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	if args.Limit > 0 {
+		requestParams["limit"] = strconv.Itoa(args.Limit)
+	}
+	if args.Offset > 0 {
+		requestParams["offset"] = strconv.Itoa(args.Offset)
+	}
+
+	// 生成签名
+	signParams, err := c.signParams(requestParams, c.v2SignType())
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建API接口URL
+	apiUrl, err := c.apiUrl(V2OrdersUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ListOrdersRes
+	// 解析响应并检查状态码，失败时按重试策略重试
+	err = c.withRetry(ctx, true, func() error {
+		body, err := c.postForm(ctx, apiUrl, signParams)
+		if err != nil {
+			return err
+		}
+		result = ListOrdersRes{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		if err := checkCode(VersionV2, result.Code, result.Message, body); err != nil {
+			return err
+		}
+		// 校验平台签名
+		return c.verifyResponse(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &result, nil
 }
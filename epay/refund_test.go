@@ -0,0 +1,87 @@
+package epay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Refund(t *testing.T) {
+	asserts := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asserts.Equal(http.MethodPost, r.Method)
+		asserts.Equal("/api.php", r.URL.Path)
+		asserts.Equal("refund", r.URL.Query().Get("act"))
+		r.ParseForm()
+		asserts.Equal("1000", r.PostForm.Get("pid"))
+		asserts.Equal("KEY", r.PostForm.Get("key"))
+		asserts.Equal("1.50", r.PostForm.Get("money"))
+		asserts.Equal("T1", r.PostForm.Get("trade_no"))
+		w.Write([]byte(`{"code":1,"msg":"退款成功"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL)
+	res, err := client.Refund(&RefundArgs{TradeNo: "T1", Money: MustParseAmount("1.50")})
+	asserts.NoError(err)
+	asserts.Equal("退款成功", res.Message)
+
+	_, err = client.Refund(&RefundArgs{TradeNo: "T1"})
+	asserts.Error(err)
+}
+
+func TestV2Refund(t *testing.T) {
+	asserts := assert.New(t)
+	merchantPrivateKey, merchantPublicKey := genTestKeyPair(t)
+	platformPrivateKey, platformPublicKey := genTestKeyPair(t)
+
+	var responseKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asserts.Equal("/api/pay/refund", r.URL.Path)
+		r.ParseForm()
+		params := map[string]string{}
+		for k := range r.PostForm {
+			params[k] = r.PostForm.Get(k)
+		}
+		asserts.Equal(SignTypeRSA, params["sign_type"])
+		asserts.Equal("O1", params["out_trade_no"])
+		asserts.Equal("R1", params["out_refund_no"])
+		asserts.Equal("1.50", params["money"])
+		asserts.NotEmpty(params["timestamp"])
+		verified, err := RSAVerify(GetSignContent(params), params["sign"], merchantPublicKey)
+		asserts.NoError(err)
+		asserts.True(verified)
+
+		sign, _ := RSASign("code=0&money=1.50&out_refund_no=R1&refund_no=P1", responseKey)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":          0,
+			"refund_no":     "P1",
+			"out_refund_no": "R1",
+			"money":         "1.50",
+			"sign":          sign,
+			"sign_type":     SignTypeRSA,
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{PartnerID: "1000", Key: merchantPrivateKey, PublicKey: platformPublicKey}, server.URL)
+	asserts.NoError(err)
+	args := &RefundArgs{OutTradeNo: "O1", OutRefundNo: "R1", Money: MustParseAmount("1.50")}
+
+	responseKey = platformPrivateKey
+	res, err := client.Refund(args)
+	asserts.NoError(err)
+	asserts.Equal("P1", res.RefundNo)
+	asserts.Equal(MustParseAmount("1.50"), res.Money)
+
+	// 响应不是平台私钥签名时拒绝
+	responseKey = merchantPrivateKey
+	_, err = client.Refund(args)
+	asserts.ErrorIs(err, ErrInvalidResponseSign)
+
+	_, err = client.Refund(&RefundArgs{OutTradeNo: "O1"})
+	asserts.Error(err)
+}
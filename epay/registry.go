@@ -0,0 +1,162 @@
+package epay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrUnknownMerchant 注册表中没有与回调pid匹配的客户端
+var ErrUnknownMerchant = errors.New("未知的商户ID")
+
+// Registry 多商户客户端注册表，并发安全，可在运行时添加或移除客户端
+// 发起订单时按名称或商户ID与网关地址选择客户端，回调通知按pid参数路由
+type Registry struct {
+	mu      sync.RWMutex
+	entries []registryEntry // 按添加顺序保存
+}
+
+type registryEntry struct {
+	name   string
+	client *Client
+}
+
+// NewRegistry 创建空的客户端注册表
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add 添加客户端，同名客户端已存在时替换
+// 不同名称的客户端不能使用相同的商户ID与网关地址
+func (r *Registry) Add(name string, client *Client) error {
+	if name == "" || client == nil || client.Config == nil {
+		return errors.New("必须提供客户端名称及已配置的客户端")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := -1
+	for i, e := range r.entries {
+		if e.name == name {
+			index = i
+		} else if sameMerchant(e.client, client) {
+			return fmt.Errorf("商户 %s@%s 已注册为 %s", client.Config.PartnerID, client.BaseUrl, e.name)
+		}
+	}
+	if index >= 0 {
+		r.entries[index].client = client
+		return nil
+	}
+	r.entries = append(r.entries, registryEntry{name: name, client: client})
+	return nil
+}
+
+// Remove 移除客户端，返回是否存在
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.name == name {
+			r.entries = append(r.entries[:i:i], r.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Get 按名称获取客户端
+func (r *Registry) Get(name string) (*Client, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.entries {
+		if e.name == name {
+			return e.client, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup 按商户ID与网关地址获取客户端，baseUrl为空时返回该商户ID的第一个客户端
+func (r *Registry) Lookup(pid, baseUrl string) (*Client, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.entries {
+		if e.client.Config.PartnerID != pid {
+			continue
+		}
+		if baseUrl == "" || e.client.BaseUrl.String() == baseUrl {
+			return e.client, true
+		}
+	}
+	return nil, false
+}
+
+// Names 返回所有客户端名称，按添加顺序排列
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+// Verify 根据回调参数中的pid选择客户端并验签
+// 同一商户ID注册在多个网关时，依次尝试直到验签通过
+func (r *Registry) Verify(params map[string]string) (*VerifyRes, error) {
+	_, res, err := r.route(params)
+	return res, err
+}
+
+// NotifyHandler 多商户异步通知处理器，按pid路由到对应客户端，其余行为同 NotifyHandler
+func (r *Registry) NotifyHandler(fn NotifyFunc) http.Handler {
+	return notifyHandler(r.route, fn)
+}
+
+// 按pid查找客户端并验签，返回验签使用的客户端
+func (r *Registry) route(params map[string]string) (*Client, *VerifyRes, error) {
+	pid := params["pid"]
+	r.mu.RLock()
+	var candidates []*Client
+	for _, e := range r.entries {
+		if e.client.Config.PartnerID == pid {
+			candidates = append(candidates, e.client)
+		}
+	}
+	r.mu.RUnlock()
+
+	var (
+		lastClient *Client
+		lastRes    *VerifyRes
+		lastErr    error
+	)
+	for _, client := range candidates {
+		res, err := client.Verify(params)
+		if errors.Is(err, ErrNotifyReplayed) || errors.Is(err, ErrNotifyExpired) {
+			// 签名已通过，无需继续尝试其他客户端
+			return client, nil, err
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if res.VerifyStatus {
+			return client, res, nil
+		}
+		lastClient, lastRes = client, res
+	}
+	if lastRes != nil {
+		return lastClient, lastRes, nil
+	}
+	if lastErr != nil {
+		return nil, nil, lastErr
+	}
+	return nil, nil, ErrUnknownMerchant
+}
+
+// 是否为同一网关下的同一商户
+func sameMerchant(a, b *Client) bool {
+	return a.Config.PartnerID == b.Config.PartnerID && a.BaseUrl.String() == b.BaseUrl.String()
+}
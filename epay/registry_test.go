@@ -0,0 +1,97 @@
+package epay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	asserts := assert.New(t)
+	brandA, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY_A"}, "http://pay-a.example.com")
+	brandB, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY_B"}, "http://pay-b.example.com")
+	other, _ := NewClient(&Config{PartnerID: "2000", Key: "KEY_C"}, "http://pay-a.example.com")
+
+	registry := NewRegistry()
+	asserts.NoError(registry.Add("a", brandA))
+	asserts.NoError(registry.Add("b", brandB))
+	asserts.NoError(registry.Add("c", other))
+	asserts.Error(registry.Add("dup", brandA))
+	asserts.Equal([]string{"a", "b", "c"}, registry.Names())
+
+	client, ok := registry.Get("b")
+	asserts.True(ok)
+	asserts.Same(brandB, client)
+	client, ok = registry.Lookup("1000", "http://pay-b.example.com")
+	asserts.True(ok)
+	asserts.Same(brandB, client)
+	client, ok = registry.Lookup("2000", "")
+	asserts.True(ok)
+	asserts.Same(other, client)
+
+	// 同一商户ID按密钥匹配到正确的客户端
+	params := GenerateParams(map[string]string{
+		"pid":          "1000",
+		"trade_no":     "T1",
+		"out_trade_no": "O1",
+		"money":        "1.00",
+		"trade_status": StatusTradeSuccess,
+	}, "KEY_B", SignTypeMD5)
+	res, err := registry.Verify(params)
+	asserts.NoError(err)
+	asserts.True(res.VerifyStatus)
+
+	params["pid"] = "3000"
+	_, err = registry.Verify(params)
+	asserts.ErrorIs(err, ErrUnknownMerchant)
+
+	asserts.True(registry.Remove("b"))
+	asserts.False(registry.Remove("b"))
+	params["pid"] = "1000"
+	res, err = registry.Verify(params)
+	asserts.NoError(err)
+	asserts.False(res.VerifyStatus)
+}
+
+func TestRegistryNotifyHandler(t *testing.T) {
+	asserts := assert.New(t)
+	store := NewMemoryNotifyStore(0)
+	clientA, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY_A"}, "http://localhost", WithNotifyStore(store, 0))
+	clientB, _ := NewClient(&Config{PartnerID: "2000", Key: "KEY_B"}, "http://localhost", WithNotifyStore(store, 0))
+	registry := NewRegistry()
+	registry.Add("a", clientA)
+	registry.Add("b", clientB)
+
+	var got []string
+	handler := registry.NotifyHandler(func(ctx context.Context, res *VerifyRes) error {
+		got = append(got, res.PID)
+		return nil
+	})
+	notify := func(pid, key string) string {
+		params := GenerateParams(map[string]string{
+			"pid":          pid,
+			"trade_no":     "T" + pid,
+			"money":        "1.00",
+			"trade_status": StatusTradeSuccess,
+		}, key, SignTypeMD5)
+		form := url.Values{}
+		for k, v := range params {
+			form.Set(k, v)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	asserts.Equal(NotifyAckSuccess, notify("1000", "KEY_A"))
+	asserts.Equal(NotifyAckSuccess, notify("2000", "KEY_B"))
+	asserts.Equal(NotifyAckFail, notify("2000", "KEY_A"))
+	asserts.Equal([]string{"1000", "2000"}, got)
+}
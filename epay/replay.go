@@ -0,0 +1,88 @@
+package epay
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// NotifyStore 已处理回调通知的存储，用于防止通知重放
+type NotifyStore interface {
+	// Mark 记录通知标识，窗口期内已记录过时返回false
+	Mark(key string, window time.Duration) (bool, error)
+	// Forget 删除通知标识，业务处理失败时调用以便平台重新通知
+	Forget(key string) error
+}
+
+var _ NotifyStore = (*MemoryNotifyStore)(nil)
+
+// MemoryNotifyStore 基于LRU的内存通知存储，超出容量时淘汰最久未使用的记录
+type MemoryNotifyStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+type notifyEntry struct {
+	key      string
+	expireAt time.Time
+}
+
+// NewMemoryNotifyStore 创建内存通知存储，capacity为最多保留的记录数
+func NewMemoryNotifyStore(capacity int) *MemoryNotifyStore {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &MemoryNotifyStore{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Mark 记录通知标识，窗口期内已记录过时返回false
+func (s *MemoryNotifyStore) Mark(key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*notifyEntry)
+		if now.Before(entry.expireAt) {
+			s.ll.MoveToFront(el)
+			return false, nil
+		}
+		// 已过期，重新记录
+		entry.expireAt = now.Add(window)
+		s.ll.MoveToFront(el)
+		return true, nil
+	}
+
+	s.items[key] = s.ll.PushFront(&notifyEntry{key: key, expireAt: now.Add(window)})
+	if s.ll.Len() > s.capacity {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*notifyEntry).key)
+	}
+	return true, nil
+}
+
+// Forget 删除通知标识
+func (s *MemoryNotifyStore) Forget(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.ll.Remove(el)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// 通知标识，由平台订单号与签名组成
+func notifyKey(params map[string]string) string {
+	return params["trade_no"] + "|" + params["sign"]
+}
@@ -0,0 +1,73 @@
+package epay
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryNotifyStore(t *testing.T) {
+	asserts := assert.New(t)
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryNotifyStore(2)
+	store.now = func() time.Time { return now }
+
+	ok, _ := store.Mark("a", time.Minute)
+	asserts.True(ok)
+	ok, _ = store.Mark("a", time.Minute)
+	asserts.False(ok)
+
+	// 窗口期过后可以重新记录
+	now = now.Add(2 * time.Minute)
+	ok, _ = store.Mark("a", time.Minute)
+	asserts.True(ok)
+
+	// 超出容量淘汰最久未使用的记录
+	store.Mark("b", time.Minute)
+	store.Mark("c", time.Minute)
+	ok, _ = store.Mark("a", time.Minute)
+	asserts.True(ok)
+
+	// Forget 后可以重新记录
+	store.Forget("a")
+	ok, _ = store.Mark("a", time.Minute)
+	asserts.True(ok)
+}
+
+func TestVerifyReplayAndTimestamp(t *testing.T) {
+	asserts := assert.New(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost",
+		WithTimestampSkew(5*time.Minute),
+		WithNotifyStore(NewMemoryNotifyStore(0), time.Hour),
+	)
+
+	params := GenerateParams(map[string]string{
+		"pid":          "1000",
+		"trade_no":     "T1",
+		"out_trade_no": "O1",
+		"money":        "1.00",
+		"trade_status": StatusTradeSuccess,
+		"timestamp":    strconv.FormatInt(time.Now().Unix(), 10),
+	}, "KEY", SignTypeMD5)
+
+	res, err := client.Verify(params)
+	asserts.NoError(err)
+	asserts.True(res.VerifyStatus)
+
+	_, err = client.Verify(params)
+	asserts.ErrorIs(err, ErrNotifyReplayed)
+
+	// 清除记录后允许平台重新通知
+	asserts.NoError(client.ForgetNotify(params))
+	_, err = client.Verify(params)
+	asserts.NoError(err)
+
+	expired := GenerateParams(map[string]string{
+		"trade_no":  "T2",
+		"timestamp": strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10),
+	}, "KEY", SignTypeMD5)
+	_, err = client.Verify(expired)
+	asserts.ErrorIs(err, ErrNotifyExpired)
+}
@@ -0,0 +1,89 @@
+package epay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// 拼接接口地址
+func (c *Client) apiUrl(apiPath string) (*url.URL, error) {
+	u, err := url.Parse(c.BaseUrl.String())
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, apiPath)
+	return u, nil
+}
+
+// 发送POST表单请求，返回响应内容
+func (c *Client) postForm(ctx context.Context, apiUrl *url.URL, params map[string]string) ([]byte, error) {
+	req, err := newFormRequest(ctx, apiUrl, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// 发送POST表单请求，网关不可用时切换到备用地址
+// idempotent为false时仅在请求未发出时切换，见 failover
+func (c *Client) postFormFailover(ctx context.Context, apiPath string, params map[string]string, idempotent bool) ([]byte, error) {
+	return c.failover(ctx, apiPath, idempotent, func(apiUrl *url.URL) (*http.Request, error) {
+		return newFormRequest(ctx, apiUrl, params)
+	})
+}
+
+// 发送GET请求，网关不可用时切换到备用地址
+func (c *Client) getFailover(ctx context.Context, apiPath string, query url.Values) ([]byte, error) {
+	return c.failover(ctx, apiPath, true, func(apiUrl *url.URL) (*http.Request, error) {
+		apiUrl.RawQuery = query.Encode()
+		return http.NewRequestWithContext(ctx, http.MethodGet, apiUrl.String(), nil)
+	})
+}
+
+func newFormRequest(ctx context.Context, apiUrl *url.URL, params map[string]string) (*http.Request, error) {
+	form := url.Values(lo.MapValues(params, func(v string, _ string) []string {
+		return []string{v}
+	}))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiUrl.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// 发送GET请求，返回响应内容
+func (c *Client) get(ctx context.Context, apiUrl *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// 执行请求并读取响应内容，5xx响应返回 *StatusError
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.getHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: body}
+	}
+	return body, nil
+}
@@ -0,0 +1,84 @@
+package epay
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 请求重试策略，网络错误或5xx响应时按指数退避重试
+// 查询类接口自动应用，API创建订单仅在 ApiCreateOrderArgs.Idempotent 为true时应用
+type RetryPolicy struct {
+	// 最大尝试次数（含首次请求），不大于1时不重试
+	MaxAttempts int
+	// 首次重试前的等待时间，之后每次翻倍
+	BaseDelay time.Duration
+	// 等待时间上限，为0时不限制
+	MaxDelay time.Duration
+	// 随机抖动比例，取值0~1，避免多个客户端同时重试
+	Jitter float64
+	// 判断错误是否可重试，为空时使用 IsRetryable
+	// 可收到网络错误、*StatusError、平台返回的 *APIError 及响应验签错误
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy 默认重试策略：最多3次，等待200ms起，上限2s，抖动20%
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// IsRetryable 默认的可重试错误：网络错误或网关返回5xx，平台业务错误不重试
+func IsRetryable(err error) bool {
+	return isGatewayFailure(err)
+}
+
+// 第attempt次重试前的等待时间，attempt从1开始
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// 按重试策略执行请求，retry为false或未配置重试策略时只执行一次
+// call需包含发送请求、解析响应及检查状态码，以便 Retryable 判断平台返回的 *APIError
+func (c *Client) withRetry(ctx context.Context, retry bool, call func() error) error {
+	policy := c.retryPolicy
+	if !retry || policy == nil || policy.MaxAttempts <= 1 {
+		return call()
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
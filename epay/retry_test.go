@@ -0,0 +1,95 @@
+package epay
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	asserts := assert.New(t)
+	var hits, failures int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/mapi.php" {
+			w.Write([]byte(`{"code":1,"trade_no":"T1"}`))
+			return
+		}
+		w.Write([]byte(`{"code":1,"trade_no":"T1","money":"1.00","status":1}`))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Jitter: 0.5}
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL, WithRetryPolicy(policy))
+
+	// 查询接口自动重试
+	atomic.StoreInt32(&failures, 2)
+	_, err := client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal(int32(3), atomic.SwapInt32(&hits, 0))
+
+	// 超过最大尝试次数后返回错误
+	atomic.StoreInt32(&failures, 3)
+	_, err = client.QueryOrder("T1", "")
+	var statusErr *StatusError
+	asserts.ErrorAs(err, &statusErr)
+	asserts.Equal(int32(3), atomic.SwapInt32(&hits, 0))
+
+	// API创建订单默认不重试
+	notifyURL, _ := url.Parse("http://localhost/notify")
+	args := &ApiCreateOrderArgs{Type: "alipay", OutTradeNo: "O1", NotifyURL: notifyURL, Money: 100}
+	atomic.StoreInt32(&failures, 1)
+	_, err = client.ApiCreateOrder(args)
+	asserts.Error(err)
+	asserts.Equal(int32(1), atomic.SwapInt32(&hits, 0))
+
+	// 调用方保证幂等时重试
+	args.Idempotent = true
+	atomic.StoreInt32(&failures, 1)
+	res, err := client.ApiCreateOrder(args)
+	asserts.NoError(err)
+	asserts.Equal("T1", res.TradeNo)
+	asserts.Equal(int32(2), atomic.SwapInt32(&hits, 0))
+}
+
+func TestRetryClassifier(t *testing.T) {
+	asserts := assert.New(t)
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Write([]byte(`{"code":-1,"msg":"系统繁忙，请稍后再试"}`))
+			return
+		}
+		w.Write([]byte(`{"code":1,"trade_no":"T1","money":"1.00","status":1}`))
+	}))
+	defer server.Close()
+
+	// 默认不重试平台业务错误
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL, WithRetryPolicy(DefaultRetryPolicy()))
+	_, err := client.QueryOrder("T1", "")
+	var apiErr *APIError
+	asserts.ErrorAs(err, &apiErr)
+	asserts.Equal(int32(1), atomic.SwapInt32(&hits, 0))
+
+	// 自定义可重试错误可以收到平台返回的 *APIError
+	policy := RetryPolicy{MaxAttempts: 2, Retryable: func(err error) bool {
+		var apiErr *APIError
+		return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "繁忙")
+	}}
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL, WithRetryPolicy(policy))
+	res, err := client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal("T1", res.TradeNo)
+	asserts.Equal(int32(2), atomic.LoadInt32(&hits))
+}
@@ -0,0 +1,61 @@
+package epay
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type returnContextKey struct{}
+
+// IsReturnRequest 判断请求是否为支付完成后浏览器的同步跳转
+// 同步跳转由浏览器发起页面导航，异步通知由平台服务端直接请求
+// 依据客户端可伪造的 Sec-Fetch-Mode、Accept 请求头判断，仅用于区分请求来源，不能作为安全边界
+func IsReturnRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if r.Header.Get("Sec-Fetch-Mode") == "navigate" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// VerifyReturn 验证同步跳转(return_url)携带的签名参数
+// 同步跳转与异步通知的签名相同，此处不记录防重放标识，以免影响异步通知的处理
+func (c *Client) VerifyReturn(r *http.Request) (*VerifyRes, error) {
+	if !IsReturnRequest(r) {
+		return nil, ErrNotReturnRequest
+	}
+	params, err := requestParams(r)
+	if err != nil {
+		return nil, err
+	}
+	verifyRes, err := c.verify(params, false)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyRes.VerifyStatus {
+		return nil, ErrInvalidSign
+	}
+	return verifyRes, nil
+}
+
+// ReturnMiddleware 同步跳转验签中间件
+// 验签通过后将结果写入请求上下文，下游通过 ReturnFromContext 获取；验签失败返回400
+func ReturnMiddleware(client *Client, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyRes, err := client.VerifyReturn(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), returnContextKey{}, verifyRes)))
+	})
+}
+
+// ReturnFromContext 获取 ReturnMiddleware 写入的验签结果
+func ReturnFromContext(ctx context.Context) (*VerifyRes, bool) {
+	verifyRes, ok := ctx.Value(returnContextKey{}).(*VerifyRes)
+	return verifyRes, ok
+}
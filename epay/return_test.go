@@ -0,0 +1,58 @@
+package epay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnMiddleware(t *testing.T) {
+	asserts := assert.New(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost",
+		WithNotifyStore(NewMemoryNotifyStore(0), time.Hour))
+
+	params := GenerateParams(map[string]string{
+		"pid":          "1000",
+		"trade_no":     "T1",
+		"out_trade_no": "O1",
+		"money":        "1.00",
+		"trade_status": StatusTradeSuccess,
+	}, "KEY", SignTypeMD5)
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	var got *VerifyRes
+	handler := ReturnMiddleware(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ReturnFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/return?"+query.Encode(), nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	asserts.Equal(http.StatusOK, rec.Code)
+	asserts.Equal("O1", got.OutTradeNo)
+
+	// 同步跳转不影响随后到达的异步通知
+	res, err := client.Verify(params)
+	asserts.NoError(err)
+	asserts.True(res.VerifyStatus)
+
+	// 非浏览器请求
+	_, err = client.VerifyReturn(httptest.NewRequest(http.MethodGet, "/return?"+query.Encode(), nil))
+	asserts.ErrorIs(err, ErrNotReturnRequest)
+
+	// 篡改参数
+	query.Set("money", "0.01")
+	req = httptest.NewRequest(http.MethodGet, "/return?"+query.Encode(), nil)
+	req.Header.Set("Sec-Fetch-Mode", "navigate")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	asserts.Equal(http.StatusBadRequest, rec.Code)
+}
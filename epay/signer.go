@@ -0,0 +1,97 @@
+package epay
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// Signer 签名器，可接入KMS、HSM等外部密钥服务
+type Signer interface {
+	// SignType 签名类型，如 SignTypeRSA、SignTypeMD5
+	SignType() string
+	// Sign 对待签名字符串生成签名
+	Sign(content string) (string, error)
+}
+
+// Verifier 验签器，用于验证平台签名
+type Verifier interface {
+	// Verify 验证待签名字符串的签名
+	Verify(content, sign string) (bool, error)
+}
+
+var (
+	_ Signer   = (*RSASigner)(nil)
+	_ Signer   = (*CryptoSigner)(nil)
+	_ Signer   = MD5Signer("")
+	_ Verifier = MD5Signer("")
+	_ Verifier = (*RSAVerifier)(nil)
+)
+
+// RSASigner 使用内存中的RSA私钥进行SHA256WithRSA签名
+type RSASigner struct {
+	Key *rsa.PrivateKey
+}
+
+func (s *RSASigner) SignType() string { return SignTypeRSA }
+
+func (s *RSASigner) Sign(content string) (string, error) {
+	return RSASignWithKey(content, s.Key)
+}
+
+// CryptoSigner 包装任意 crypto.Signer 进行SHA256WithRSA签名
+// 适用于私钥不离开KMS、PKCS#11或ssh-agent的场景
+type CryptoSigner struct {
+	Signer crypto.Signer
+}
+
+func (s *CryptoSigner) SignType() string { return SignTypeRSA }
+
+func (s *CryptoSigner) Sign(content string) (string, error) {
+	if _, ok := s.Signer.Public().(*rsa.PublicKey); !ok {
+		return "", errors.New("仅支持RSA密钥")
+	}
+	digest := sha256.Sum256([]byte(content))
+	signature, err := s.Signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// MD5Signer 使用商户MD5密钥签名及验签
+type MD5Signer string
+
+func (s MD5Signer) SignType() string { return SignTypeMD5 }
+
+func (s MD5Signer) Sign(content string) (string, error) {
+	return MD5String(content, string(s)), nil
+}
+
+func (s MD5Signer) Verify(content, sign string) (bool, error) {
+	return sign == MD5String(content, string(s)), nil
+}
+
+// RSAVerifier 使用RSA公钥验证SHA256WithRSA签名
+type RSAVerifier struct {
+	Key *rsa.PublicKey
+}
+
+func (v *RSAVerifier) Verify(content, sign string) (bool, error) {
+	return RSAVerifyWithKey(content, sign, v.Key)
+}
+
+// SignParams 使用签名器生成加签参数，返回新的参数表
+func SignParams(params map[string]string, signer Signer) (map[string]string, error) {
+	newParams := copyParams(params)
+	sign, err := signer.Sign(GetSignContent(newParams))
+	if err != nil {
+		return nil, err
+	}
+	newParams["sign"] = sign
+	newParams["sign_type"] = signer.SignType()
+	return newParams, nil
+}
@@ -0,0 +1,157 @@
+package epay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	V2TransferSubmitUrl  = "/api/transfer/submit"  // v2 发起转账
+	V2TransferQueryUrl   = "/api/transfer/query"   // v2 查询转账
+	V2TransferBalanceUrl = "/api/transfer/balance" // v2 查询可用余额
+)
+
+// TransferService 转账接口，仅V2接口支持
+type TransferService struct {
+	client *Client
+}
+
+// Transfer 返回转账接口
+func (c *Client) Transfer() *TransferService {
+	return &TransferService{client: c}
+}
+
+// Submit 发起转账
+func (s *TransferService) Submit(args *TransferArgs) (*TransferRes, error) {
+	return s.SubmitWithContext(context.Background(), args)
+}
+
+// SubmitWithContext 同Submit，通过ctx控制请求的超时与取消
+func (s *TransferService) SubmitWithContext(ctx context.Context, args *TransferArgs) (*TransferRes, error) {
+	if args.Type == "" || args.Account == "" || args.Money <= 0 {
+		return nil, errors.New("必须提供转账方式、收款账号和转账金额")
+	}
+
+	// 构建请求参数
+	requestParams := map[string]string{
+		"type":    args.Type,
+		"account": args.Account,
+		"money":   args.Money.String(),
+	}
+
+	// 添加可选参数
+	if args.Name != "" {
+		requestParams["name"] = args.Name
+	}
+	if args.Remark != "" {
+		requestParams["remark"] = args.Remark
+	}
+	if args.OutBizNo != "" {
+		requestParams["out_biz_no"] = args.OutBizNo
+	}
+
+	var result TransferRes
+	if err := s.post(ctx, V2TransferSubmitUrl, requestParams, false, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Query 查询转账结果，平台转账单号与商户转账单号二选一
+func (s *TransferService) Query(bizNo, outBizNo string) (*TransferQueryRes, error) {
+	return s.QueryWithContext(context.Background(), bizNo, outBizNo)
+}
+
+// QueryWithContext 同Query，通过ctx控制请求的超时与取消
+func (s *TransferService) QueryWithContext(ctx context.Context, bizNo, outBizNo string) (*TransferQueryRes, error) {
+	requestParams := map[string]string{}
+
+	// 至少需要传入一个转账单号
+	if bizNo != "" {
+		requestParams["biz_no"] = bizNo
+	} else if outBizNo != "" {
+		requestParams["out_biz_no"] = outBizNo
+	} else {
+		return nil, errors.New("必须提供平台转账单号或商户转账单号")
+	}
+
+	var result TransferQueryRes
+	if err := s.post(ctx, V2TransferQueryUrl, requestParams, true, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Balance 查询可用转账余额
+func (s *TransferService) Balance() (*TransferBalanceRes, error) {
+	return s.BalanceWithContext(context.Background())
+}
+
+// BalanceWithContext 同Balance，通过ctx控制请求的超时与取消
+func (s *TransferService) BalanceWithContext(ctx context.Context) (*TransferBalanceRes, error) {
+	var result TransferBalanceRes
+	if err := s.post(ctx, V2TransferBalanceUrl, map[string]string{}, true, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// 转账接口响应，用于读取返回状态码
+type transferResponse interface {
+	status() (int, string)
+}
+
+func (r *TransferRes) status() (int, string)        { return r.Code, r.Message }
+func (r *TransferQueryRes) status() (int, string)   { return r.Code, r.Message }
+func (r *TransferBalanceRes) status() (int, string) { return r.Code, r.Message }
+
+// 签名并发送请求，检查状态码并校验平台签名，retry为true时按重试策略重试
+func (s *TransferService) post(ctx context.Context, apiPath string, requestParams map[string]string, retry bool, result transferResponse) error {
+	c := s.client
+	version, err := c.version(ctx)
+	if err != nil {
+		return err
+	}
+	if version != VersionV2 {
+		return ErrUnsupported
+	}
+
+	requestParams["pid"] = c.Config.PartnerID
+	requestParams["timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+
+	// 生成签名
+	signParams, err := c.signParams(requestParams, c.v2SignType())
+	if err != nil {
+		return err
+	}
+
+	// 构建API接口URL
+	apiUrl, err := c.apiUrl(apiPath)
+	if err != nil {
+		return err
+	}
+
+	// 解析响应并检查状态码，失败时按重试策略重试
+	return c.withRetry(ctx, retry, func() error {
+		body, err := c.postForm(ctx, apiUrl, signParams)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(body, result); err != nil {
+			return err
+		}
+
+		// 检查响应状态码
+		code, message := result.status()
+		if err := checkCode(VersionV2, code, message, body); err != nil {
+			return err
+		}
+
+		// 校验平台签名
+		return c.verifyResponse(body)
+	})
+}
@@ -0,0 +1,97 @@
+package epay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 使用平台私钥对响应签名
+func writeSignedResponse(w http.ResponseWriter, privateKey string, fields map[string]string) {
+	params := map[string]string{"code": "0"}
+	body := map[string]interface{}{"code": 0, "sign_type": SignTypeRSA}
+	for k, v := range fields {
+		params[k] = v
+		body[k] = v
+	}
+	body["sign"], _ = RSASign(GetSignContent(params), privateKey)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestTransfer(t *testing.T) {
+	asserts := assert.New(t)
+	merchantPrivateKey, merchantPublicKey := genTestKeyPair(t)
+	platformPrivateKey, platformPublicKey := genTestKeyPair(t)
+
+	var hits, failures int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		r.ParseForm()
+		params := map[string]string{}
+		for k := range r.PostForm {
+			params[k] = r.PostForm.Get(k)
+		}
+		asserts.Equal("1000", params["pid"])
+		asserts.NotEmpty(params["timestamp"])
+		verified, err := RSAVerify(GetSignContent(params), params["sign"], merchantPublicKey)
+		asserts.NoError(err)
+		asserts.True(verified)
+
+		switch r.URL.Path {
+		case V2TransferSubmitUrl:
+			asserts.Equal(TransferTypeAlipay, params["type"])
+			asserts.Equal("10.00", params["money"])
+			writeSignedResponse(w, platformPrivateKey, map[string]string{"biz_no": "B1", "out_biz_no": params["out_biz_no"]})
+		case V2TransferQueryUrl:
+			writeSignedResponse(w, platformPrivateKey, map[string]string{"biz_no": "B1", "money": "10.00"})
+		case V2TransferBalanceUrl:
+			writeSignedResponse(w, platformPrivateKey, map[string]string{"available_money": "99.50"})
+		}
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	client, err := NewClient(&Config{PartnerID: "1000", Key: merchantPrivateKey, PublicKey: platformPublicKey}, server.URL, WithRetryPolicy(policy))
+	asserts.NoError(err)
+	transfer := client.Transfer()
+	args := &TransferArgs{Type: TransferTypeAlipay, Account: "a@example.com", Money: MustParseAmount("10"), OutBizNo: "OB1"}
+
+	res, err := transfer.Submit(args)
+	asserts.NoError(err)
+	asserts.Equal("B1", res.BizNo)
+
+	// 发起转账从不重试，避免重复付款
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&failures, 1)
+	_, err = transfer.Submit(args)
+	asserts.Error(err)
+	asserts.Equal(int32(1), atomic.LoadInt32(&hits))
+
+	// 查询接口按重试策略重试
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&failures, 1)
+	queryRes, err := transfer.Query("B1", "")
+	asserts.NoError(err)
+	asserts.Equal(MustParseAmount("10"), queryRes.Money)
+	asserts.Equal(int32(2), atomic.LoadInt32(&hits))
+
+	balance, err := transfer.Balance()
+	asserts.NoError(err)
+	asserts.Equal(MustParseAmount("99.50"), balance.AvailableMoney)
+
+	// V1接口不支持转账
+	atomic.StoreInt32(&hits, 0)
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY"}, server.URL)
+	_, err = client.Transfer().Submit(args)
+	asserts.ErrorIs(err, ErrUnsupported)
+	asserts.Equal(int32(0), atomic.LoadInt32(&hits))
+}
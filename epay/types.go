@@ -1,6 +1,12 @@
 package epay
 
-import "net/url"
+import (
+	"crypto/rsa"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
 
 const StatusTradeSuccess = "TRADE_SUCCESS"
 
@@ -40,10 +46,53 @@ type Config struct {
 	PartnerID string // 商户ID
 	Key       string // MD5密钥或RSA私钥
 	PublicKey string // 平台公钥(用于验证签名)
+
+	// 接口版本，为空时配置了平台公钥即使用V2接口，否则使用V1接口
+	// V2接口未配置平台公钥时使用MD5签名
+	Version Version
+
+	// 已解析的RSA密钥，设置后优先于Key、PublicKey使用
+	// 可通过 LoadPrivateKeyFile、LoadPublicKeyFromCertificate 等方法加载
+	RSAPrivateKey *rsa.PrivateKey
+	RSAPublicKey  *rsa.PublicKey
+
+	// 自定义签名器与验签器，设置后优先于上述密钥使用
+	// 私钥保存在KMS、HSM中时可使用 CryptoSigner，平台轮换公钥时可使用 KeySet
+	Signer   Signer
+	Verifier Verifier
 }
 type Client struct {
 	Config  *Config
 	BaseUrl *url.URL
+
+	httpClient         *http.Client // 为空时使用http.DefaultClient
+	userAgent          string
+	skipResponseVerify bool // 关闭V2响应验签
+
+	timestampSkew time.Duration // 回调时间戳允许的误差，0表示不校验
+	notifyStore   NotifyStore   // 回调防重放存储，为空表示不校验
+	notifyWindow  time.Duration // 回调防重放窗口期
+
+	// V2接口使用的签名器与验签器，避免每次签名重复解析密钥
+	keyOnce  sync.Once
+	keyErr   error
+	signer   Signer
+	verifier Verifier
+
+	// 网关地址列表及熔断器配置
+	backupUrls       []string
+	endpoints        []*endpoint
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	retryPolicy *RetryPolicy // 为空时不重试
+
+	// VersionAuto 探测到的接口版本
+	versionMu       sync.Mutex
+	detectedVersion Version
+	versionProbe    *versionProbe
+	versionErr      error
+	versionErrUntil time.Time // 为零值时错误始终有效
 }
 
 type CreateOrderArgs struct {
@@ -54,7 +103,7 @@ type CreateOrderArgs struct {
 	// 商品名称
 	Name string
 	// 金额
-	Money string
+	Money Amount
 	// 设备类型
 	Device    DeviceType
 	NotifyUrl *url.URL
@@ -72,7 +121,7 @@ type ApiCreateOrderArgs struct {
 	NotifyURL  *url.URL `json:"notify_url"`   // 异步通知地址
 	ReturnURL  *url.URL `json:"return_url"`   // 跳转通知地址
 	Name       string   `json:"name"`         // 商品名称
-	Money      string   `json:"money"`        // 商品金额
+	Money      Amount   `json:"money"`        // 商品金额
 	ClientIP   string   `json:"clientip"`     // 用户IP地址
 	Timestamp  string   `json:"timestamp"`    // 当前时间戳
 	Sign       string   `json:"sign"`         // 签名字符串
@@ -84,6 +133,10 @@ type ApiCreateOrderArgs struct {
 	AuthCode  string     `json:"auth_code,omitempty"`  // 被扫支付授权码
 	SubOpenID string     `json:"sub_openid,omitempty"` // 用户Openid
 	SubAppID  string     `json:"sub_appid,omitempty"`  // 公众号AppId
+
+	// 调用方保证out_trade_no唯一且平台会拒绝重复订单时设置为true
+	// 设置后按 WithRetryPolicy 配置的策略重试，否则只请求一次
+	Idempotent bool `json:"-"`
 }
 
 // API支付响应
@@ -131,7 +184,7 @@ type ApiOrderQueryRes struct {
 	// 商品名称
 	Name string `json:"name"`
 	// 金额
-	Money string `json:"money"`
+	Money Amount `json:"money"`
 	// 支付状态 1支付成功，0未支付
 	Status int `json:"status"`
 	// 业务扩展参数
@@ -140,27 +193,326 @@ type ApiOrderQueryRes struct {
 	Buyer string `json:"buyer"`
 
 	// V2特有字段
-	RefundMoney string `json:"refundmoney,omitempty"` // 已退款金额
+	RefundMoney Amount `json:"refundmoney,omitempty"` // 已退款金额
 	ClientIP    string `json:"clientip,omitempty"`    // 用户IP
 	Timestamp   string `json:"timestamp,omitempty"`   // 时间戳
 	Sign        string `json:"sign,omitempty"`        // 签名
 	SignType    string `json:"sign_type,omitempty"`   // 签名类型
 }
 
+// RefundArgs 退款参数，系统订单号与商户订单号二选一
+type RefundArgs struct {
+	// 易支付订单号
+	TradeNo string
+	// 商户订单号
+	OutTradeNo string
+	// 退款金额，可小于订单金额实现部分退款
+	Money Amount
+	// 商户退款单号（V2可选）
+	OutRefundNo string
+}
+
+// RefundRes 退款响应
+type RefundRes struct {
+	// 返回状态码 v1是1成功，v2是0成功
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+
+	// V2特有字段
+	RefundNo    string `json:"refund_no,omitempty"`     // 平台退款单号
+	OutRefundNo string `json:"out_refund_no,omitempty"` // 商户退款单号
+	TradeNo     string `json:"trade_no,omitempty"`      // 易支付订单号
+	Money       Amount `json:"money,omitempty"`         // 本次退款金额，累计计入订单查询的RefundMoney
+	ReduceMoney Amount `json:"reducemoney,omitempty"`   // 扣减商户余额
+	Timestamp   string `json:"timestamp,omitempty"`     // 时间戳
+	Sign        string `json:"sign,omitempty"`          // 签名
+	SignType    string `json:"sign_type,omitempty"`     // 签名类型
+}
+
+// RefundQueryRes 退款查询响应
+type RefundQueryRes struct {
+	// 返回状态码 0成功，其他失败
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+	// 平台退款单号
+	RefundNo string `json:"refund_no"`
+	// 商户退款单号
+	OutRefundNo string `json:"out_refund_no"`
+	// 易支付订单号
+	TradeNo string `json:"trade_no"`
+	// 商户订单号
+	OutTradeNo string `json:"out_trade_no"`
+	// 退款金额
+	Money Amount `json:"money"`
+	// 扣减商户余额
+	ReduceMoney Amount `json:"reducemoney"`
+	// 退款状态 1退款成功，0退款中
+	Status int `json:"status"`
+	// 退款申请时间
+	AddTime string `json:"addtime"`
+	// 退款完成时间
+	EndTime string `json:"endtime"`
+
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳
+	Sign      string `json:"sign,omitempty"`      // 签名
+	SignType  string `json:"sign_type,omitempty"` // 签名类型
+}
+
+// CloseOrderRes 关闭订单响应
+type CloseOrderRes struct {
+	// 返回状态码 0成功，其他失败
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳
+	Sign      string `json:"sign,omitempty"`      // 签名
+	SignType  string `json:"sign_type,omitempty"` // 签名类型
+}
+
+// MerchantInfoRes 商户信息响应，V1与V2字段已统一
+type MerchantInfoRes struct {
+	// 返回状态码 v1是1成功，v2是0成功
+	Code int
+	// 返回信息
+	Message string
+	// 商户ID
+	PID string
+	// 商户状态 1正常，0封禁
+	Status int
+	// 支付权限 1开启，0关闭（V2）
+	PayStatus int
+	// 结算权限 1开启，0关闭（V2）
+	SettleStatus int
+	// 商户余额
+	Money Amount
+	// 结算方式 1支付宝，2微信，3QQ钱包，4银行卡
+	SettleType int
+	// 结算账号
+	SettleAccount string
+	// 结算账号姓名
+	SettleName string
+	// 订单总数
+	Orders int
+	// 今日订单数
+	OrderToday int
+	// 昨日订单数
+	OrderLastday int
+
+	Timestamp string // 时间戳
+	Sign      string // 签名
+	SignType  string // 签名类型
+}
+
+// ListOrdersArgs 订单列表查询参数
+type ListOrdersArgs struct {
+	// 偏移量
+	Offset int
+	// 每页数量，最大50
+	Limit int
+}
+
+// ListOrdersRes 订单列表响应
+type ListOrdersRes struct {
+	// 返回状态码 v1是1成功，v2是0成功
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+	// 订单列表
+	Data []ApiOrderQueryRes `json:"data"`
+
+	// V2特有字段
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳
+	Sign      string `json:"sign,omitempty"`      // 签名
+	SignType  string `json:"sign_type,omitempty"` // 签名类型
+}
+
+// Settlement 结算记录
+type Settlement struct {
+	// 结算记录ID
+	ID int
+	// 结算方式 1支付宝，2微信，3QQ钱包，4银行卡
+	Type int
+	// 结算账号
+	Account string
+	// 结算账号姓名
+	Username string
+	// 结算金额
+	Money Amount
+	// 实际到账金额
+	RealMoney Amount
+	// 手续费（结算金额-实际到账金额）
+	Fee Amount
+	// 结算状态 1已完成，0待结算，2正在结算，3结算失败
+	Status int
+	// 创建时间
+	AddTime string
+	// 完成时间
+	EndTime string
+}
+
+// ListSettlementsRes 结算记录响应
+type ListSettlementsRes struct {
+	// 返回状态码 1成功，其他失败
+	Code int
+	// 返回信息
+	Message string
+	// 结算记录
+	Data []Settlement
+}
+
+// 转账方式
+const (
+	TransferTypeAlipay = "alipay" // 支付宝
+	TransferTypeWxpay  = "wxpay"  // 微信
+	TransferTypeQQpay  = "qqpay"  // QQ钱包
+	TransferTypeBank   = "bank"   // 银行卡
+)
+
+// TransferArgs 转账参数
+type TransferArgs struct {
+	// 转账方式
+	Type string
+	// 收款账号
+	Account string
+	// 收款人姓名
+	Name string
+	// 转账金额
+	Money Amount
+	// 转账备注
+	Remark string
+	// 商户转账单号
+	OutBizNo string
+}
+
+// TransferRes 转账响应
+type TransferRes struct {
+	// 返回状态码 0成功，其他失败
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+	// 转账状态 1成功，0处理中
+	Status int `json:"status"`
+	// 平台转账单号
+	BizNo string `json:"biz_no"`
+	// 商户转账单号
+	OutBizNo string `json:"out_biz_no"`
+	// 第三方转账单号
+	OrderID string `json:"orderid"`
+	// 转账完成时间
+	PayDate string `json:"paydate"`
+	// 转账手续费
+	Cost Amount `json:"cost"`
+
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳
+	Sign      string `json:"sign,omitempty"`      // 签名
+	SignType  string `json:"sign_type,omitempty"` // 签名类型
+}
+
+// TransferQueryRes 转账查询响应
+type TransferQueryRes struct {
+	// 返回状态码 0成功，其他失败
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+	// 平台转账单号
+	BizNo string `json:"biz_no"`
+	// 商户转账单号
+	OutBizNo string `json:"out_biz_no"`
+	// 第三方转账单号
+	OrderID string `json:"orderid"`
+	// 转账方式
+	Type string `json:"type"`
+	// 收款账号
+	Account string `json:"account"`
+	// 收款人姓名
+	Name string `json:"name"`
+	// 转账金额
+	Money Amount `json:"money"`
+	// 转账手续费
+	Cost Amount `json:"cost"`
+	// 转账状态 1成功，0处理中，2失败
+	Status int `json:"status"`
+	// 失败原因
+	ErrMsg string `json:"errmsg"`
+	// 创建时间
+	AddTime string `json:"addtime"`
+	// 完成时间
+	PayTime string `json:"paytime"`
+
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳
+	Sign      string `json:"sign,omitempty"`      // 签名
+	SignType  string `json:"sign_type,omitempty"` // 签名类型
+}
+
+// TransferBalanceRes 可用转账余额响应
+type TransferBalanceRes struct {
+	// 返回状态码 0成功，其他失败
+	Code int `json:"code"`
+	// 返回信息
+	Message string `json:"msg"`
+	// 可用余额
+	AvailableMoney Amount `json:"available_money"`
+
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳
+	Sign      string `json:"sign,omitempty"`      // 签名
+	SignType  string `json:"sign_type,omitempty"` // 签名类型
+}
+
 // VerifyRes 验证结果
 type VerifyRes struct {
+	// 商户ID
+	PID string `mapstructure:"pid"`
 	// 支付类型
-	Type string
+	Type string `mapstructure:"type"`
 	// 易支付订单号
 	TradeNo string `mapstructure:"trade_no"`
 	// 商家订单号
 	OutTradeNo string `mapstructure:"out_trade_no"`
+	// 第三方订单号
+	ApiTradeNo string `mapstructure:"api_trade_no"`
 	// 商品名称
-	Name string
+	Name string `mapstructure:"name"`
 	// 金额
-	Money string
+	Money Amount `mapstructure:"money"`
 	// 订单支付状态
 	TradeStatus string `mapstructure:"trade_status"`
+	// 业务扩展参数
+	Param string `mapstructure:"param"`
+	// 支付者账号
+	Buyer string `mapstructure:"buyer"`
+	// 创建订单时间
+	AddTime string `mapstructure:"addtime"`
+	// 完成交易时间
+	EndTime string `mapstructure:"endtime"`
+	// 时间戳（V2）
+	Timestamp string `mapstructure:"timestamp"`
+	// 完整的回调参数，包含尚未解析的字段
+	Params map[string]string `mapstructure:"-"`
 	// 签名检验
 	VerifyStatus bool `mapstructure:"-"`
 }
+
+// OrderExpectation 回调通知的预期订单信息
+type OrderExpectation struct {
+	// 商户订单号
+	OutTradeNo string
+	// 订单金额
+	Money Amount
+	// 支付方式，为空时不校验
+	Type string
+	// 交易状态，为空时默认 TRADE_SUCCESS
+	TradeStatus string
+}
+
+// Mismatch 回调通知与预期不一致的字段
+type Mismatch struct {
+	// 参数名
+	Field string
+	// 预期值
+	Expected string
+	// 实际值
+	Actual string
+}
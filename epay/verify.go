@@ -1,41 +1,227 @@
 package epay
 
-import "github.com/mitchellh/mapstructure"
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
 
 // Verify 验证回调参数是否符合签名
 // 验签流程：
 // 1. 获取待签名字符串（过滤参数 -> 排序 -> 生成URL字符串）
 // 2. 根据签名类型选择验证方法：
 //   - SignTypeRSA: 使用平台公钥进行RSA验签（SHA256WithRSA）
-//   - SignTypeMD5: 使用MD5密钥进行验证
+//   - SignTypeMD5: 使用MD5密钥进行验证，配置了平台公钥时不接受MD5签名
 //
 // 注意：
 // - 商户私钥(Key)用于请求时签名
 // - 平台公钥(PublicKey)用于验证平台返回数据的签名
+// - 开启防重放时，同一通知在窗口期内只能验证通过一次
 func (c *Client) Verify(params map[string]string) (*VerifyRes, error) {
+	return c.verify(params, true)
+}
+
+// 验证签名，markNotify为false时不记录防重放标识（用于同步跳转）
+func (c *Client) verify(params map[string]string, markNotify bool) (*VerifyRes, error) {
 	sign := params["sign"]
 	signType := params["sign_type"]
 	var verifyRes VerifyRes
 
-	// 从 map 映射到 struct 上
-	err := mapstructure.Decode(params, &verifyRes)
+	// 从 map 映射到 struct 上，金额等字段通过 TextUnmarshaler 解析
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.TextUnmarshallerHookFunc(),
+		Result:     &verifyRes,
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := decoder.Decode(params); err != nil {
+		return nil, err
+	}
+	verifyRes.Params = make(map[string]string, len(params))
+	for k, v := range params {
+		verifyRes.Params[k] = v
+	}
 
 	// 准备验证签名
 	urlString := GetSignContent(params)
 
-	// 根据签名类型和是否提供PublicKey来决定验证方式
-	if signType == SignTypeRSA && c.Config.PublicKey != "" {
-		verified, err := RSAVerify(urlString, sign, c.Config.PublicKey)
-		if err != nil {
+	// 配置了平台公钥时只接受RSA签名，防止以伪造的MD5签名绕过验签
+	// 未配置商户MD5密钥时不进行MD5验签
+	verified := false
+	if c.useRSA() {
+		if signType == SignTypeRSA {
+			if err := c.loadKeys(); err != nil {
+				return nil, err
+			}
+			if verified, err = c.verifier.Verify(urlString, sign); err != nil {
+				return nil, err
+			}
+		}
+	} else if c.Config.Key != "" {
+		verified, _ = MD5Signer(c.Config.Key).Verify(urlString, sign)
+	}
+	verifyRes.VerifyStatus = verified
+
+	// 签名通过后校验时间戳及重放
+	if verifyRes.VerifyStatus {
+		if err := c.checkTimestamp(params); err != nil {
 			return nil, err
 		}
-		verifyRes.VerifyStatus = verified
-	} else {
-		// 默认MD5验证
-		verifyRes.VerifyStatus = sign == MD5String(urlString, c.Config.Key)
+		if markNotify {
+			if err := c.markNotify(params); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return &verifyRes, nil
 }
+
+// VerifyExpected 验证回调签名，并确认商户ID、商户订单号、金额及交易状态与预期一致
+// 签名错误返回 ErrInvalidSign，字段不一致返回 *ExpectationError，其中列出所有不一致的字段
+// 开启防重放时会记录该通知，在 NotifyHandler 回调中应使用 VerifyRes.CheckExpected
+func (c *Client) VerifyExpected(params map[string]string, expected OrderExpectation) (*VerifyRes, error) {
+	verifyRes, err := c.Verify(params)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyRes.VerifyStatus {
+		return nil, ErrInvalidSign
+	}
+	if mismatches := verifyRes.CheckExpected(c.Config.PartnerID, expected); len(mismatches) > 0 {
+		return verifyRes, &ExpectationError{Mismatches: mismatches}
+	}
+	return verifyRes, nil
+}
+
+// CheckExpected 比较已验签的回调与预期订单，返回所有不一致的字段
+// 不重新验签，也不记录防重放标识，可在 NotifyHandler 回调中使用
+func (r *VerifyRes) CheckExpected(pid string, expected OrderExpectation) []Mismatch {
+	tradeStatus := expected.TradeStatus
+	if tradeStatus == "" {
+		tradeStatus = StatusTradeSuccess
+	}
+
+	var mismatches []Mismatch
+	check := func(field, want, got string) {
+		if want != got {
+			mismatches = append(mismatches, Mismatch{Field: field, Expected: want, Actual: got})
+		}
+	}
+	check("pid", pid, r.PID)
+	check("out_trade_no", expected.OutTradeNo, r.OutTradeNo)
+	check("money", expected.Money.String(), r.Money.String())
+	check("trade_status", tradeStatus, r.TradeStatus)
+	if expected.Type != "" {
+		check("type", expected.Type, r.Type)
+	}
+	return mismatches
+}
+
+// ForgetNotify 清除回调通知的防重放记录
+// 业务处理失败需要平台重新通知时调用
+func (c *Client) ForgetNotify(params map[string]string) error {
+	if c.notifyStore == nil {
+		return nil
+	}
+	return c.notifyStore.Forget(notifyKey(params))
+}
+
+// 校验回调时间戳，V2通知必须携带timestamp
+func (c *Client) checkTimestamp(params map[string]string) error {
+	if c.timestampSkew <= 0 {
+		return nil
+	}
+	timestamp := params["timestamp"]
+	if timestamp == "" {
+		if params["sign_type"] == SignTypeRSA {
+			return ErrNotifyExpired
+		}
+		return nil
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrNotifyExpired
+	}
+	diff := time.Since(time.Unix(unix, 0))
+	if diff > c.timestampSkew || diff < -c.timestampSkew {
+		return ErrNotifyExpired
+	}
+	return nil
+}
+
+// 记录回调通知，窗口期内重复的通知返回 ErrNotifyReplayed
+func (c *Client) markNotify(params map[string]string) error {
+	if c.notifyStore == nil {
+		return nil
+	}
+	ok, err := c.notifyStore.Mark(notifyKey(params), c.notifyWindow)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotifyReplayed
+	}
+	return nil
+}
+
+// 验证V2接口响应签名，可通过 WithoutResponseVerify 关闭
+// 响应中的数组、对象及空值不参与签名，与PHP端逻辑保持一致
+func (c *Client) verifyResponse(body []byte) error {
+	if c.skipResponseVerify {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+
+	params := make(map[string]string, len(fields))
+	for k, v := range fields {
+		switch v := v.(type) {
+		case string:
+			params[k] = v
+		case json.Number:
+			params[k] = v.String()
+		case bool:
+			if v {
+				params[k] = "1"
+			}
+		}
+	}
+
+	// 缺少签名或签名格式错误均视为验签失败
+	sign := params["sign"]
+	if sign == "" {
+		return ErrInvalidResponseSign
+	}
+
+	// 未配置平台公钥时使用商户MD5密钥验签
+	var verified bool
+	var err error
+	if c.useRSA() {
+		if _, decodeErr := base64.StdEncoding.DecodeString(sign); decodeErr != nil {
+			return ErrInvalidResponseSign
+		}
+		verified, err = c.rsaVerify(GetSignContent(params), sign)
+	} else {
+		if c.Config.Key == "" {
+			return ErrInvalidResponseSign
+		}
+		verified, err = MD5Signer(c.Config.Key).Verify(GetSignContent(params), sign)
+	}
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrInvalidResponseSign
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package epay
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 生成测试用的RSA密钥对，返回不带头尾的base64私钥与公钥
+func genTestKeyPair(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PrivateKey(key)),
+		base64.StdEncoding.EncodeToString(pub)
+}
+
+func TestVerifyResponse(t *testing.T) {
+	asserts := assert.New(t)
+	privateKey, publicKey := genTestKeyPair(t)
+	client, err := NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey}, "http://localhost")
+	asserts.NoError(err)
+
+	// 平台按照相同规则对响应签名，数字字段参与签名
+	signContent := "code=0&money=1.00&refund_no=R1&status=1"
+	sign, err := RSASign(signContent, privateKey)
+	asserts.NoError(err)
+	body, _ := json.Marshal(map[string]interface{}{
+		"code":      0,
+		"msg":       "",
+		"refund_no": "R1",
+		"money":     "1.00",
+		"status":    1,
+		"data":      []string{"ignored"},
+		"sign":      sign,
+		"sign_type": SignTypeRSA,
+	})
+	asserts.NoError(client.verifyResponse(body))
+
+	// 篡改字段后验签失败
+	tampered, _ := json.Marshal(map[string]interface{}{
+		"code":      0,
+		"refund_no": "R1",
+		"money":     "9.00",
+		"status":    1,
+		"sign":      sign,
+		"sign_type": SignTypeRSA,
+	})
+	asserts.ErrorIs(client.verifyResponse(tampered), ErrInvalidResponseSign)
+}
+
+func TestV2QueryOrderVerifiesResponse(t *testing.T) {
+	asserts := assert.New(t)
+	privateKey, publicKey := genTestKeyPair(t)
+	_, otherPublicKey := genTestKeyPair(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sign, _ := RSASign("code=0&money=1.00&status=1&trade_no=T1", privateKey)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":      0,
+			"trade_no":  "T1",
+			"money":     "1.00",
+			"status":    1,
+			"sign":      sign,
+			"sign_type": SignTypeRSA,
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey}, server.URL)
+	res, err := client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal(1, res.Status)
+
+	// 平台公钥不匹配时拒绝响应
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: otherPublicKey}, server.URL)
+	_, err = client.QueryOrder("T1", "")
+	asserts.ErrorIs(err, ErrInvalidResponseSign)
+
+	// 显式关闭验签
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: otherPublicKey}, server.URL, WithoutResponseVerify())
+	_, err = client.QueryOrder("T1", "")
+	asserts.NoError(err)
+}
+
+func TestVerifyExpected(t *testing.T) {
+	asserts := assert.New(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY"}, "http://localhost")
+	params := GenerateParams(map[string]string{
+		"pid":          "1000",
+		"type":         "alipay",
+		"trade_no":     "T1",
+		"out_trade_no": "O1",
+		"money":        "0.01",
+		"trade_status": StatusTradeSuccess,
+	}, "KEY", SignTypeMD5)
+
+	res, err := client.VerifyExpected(params, OrderExpectation{OutTradeNo: "O1", Money: MustParseAmount("0.01")})
+	asserts.NoError(err)
+	asserts.Equal("T1", res.TradeNo)
+	asserts.Equal("1000", res.PID)
+	asserts.Equal(params["sign"], res.Params["sign"])
+
+	_, err = client.VerifyExpected(params, OrderExpectation{OutTradeNo: "O1", Money: MustParseAmount("100"), Type: "wxpay"})
+	var expErr *ExpectationError
+	asserts.True(errors.As(err, &expErr))
+	asserts.Equal([]Mismatch{
+		{Field: "money", Expected: "100.00", Actual: "0.01"},
+		{Field: "type", Expected: "wxpay", Actual: "alipay"},
+	}, expErr.Mismatches)
+
+	params["money"] = "100.00"
+	_, err = client.VerifyExpected(params, OrderExpectation{OutTradeNo: "O1", Money: MustParseAmount("100")})
+	asserts.ErrorIs(err, ErrInvalidSign)
+}
+
+func TestVerifyRejectsForgedMD5(t *testing.T) {
+	asserts := assert.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	asserts.NoError(err)
+
+	configs := map[string]*Config{
+		"rsa keys": {PartnerID: "1000", RSAPrivateKey: key, RSAPublicKey: &key.PublicKey},
+		"signer":   {PartnerID: "1000", Signer: &CryptoSigner{Signer: key}, Verifier: NewKeySet(PlatformKey{ID: "k1", Key: &key.PublicKey})},
+	}
+	for name, config := range configs {
+		client, err := NewClient(config, "http://localhost")
+		asserts.NoError(err, name)
+
+		// 未配置商户MD5密钥时，以空密钥伪造的MD5签名不能通过验签
+		params := GenerateParams(map[string]string{
+			"pid":          "1000",
+			"trade_no":     "T1",
+			"out_trade_no": "O1",
+			"money":        "100.00",
+			"trade_status": StatusTradeSuccess,
+		}, "", SignTypeMD5)
+		res, err := client.Verify(params)
+		asserts.NoError(err, name)
+		asserts.False(res.VerifyStatus, name)
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"code":      0,
+			"trade_no":  "T1",
+			"sign":      MD5String("code=0&trade_no=T1", ""),
+			"sign_type": SignTypeMD5,
+		})
+		asserts.ErrorIs(client.verifyResponse(body), ErrInvalidResponseSign, name)
+	}
+
+	// 配置了平台公钥时拒绝MD5签名的回调
+	privateKey, publicKey := genTestKeyPair(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey}, "http://localhost")
+	params := GenerateParams(map[string]string{"pid": "1000", "trade_no": "T1"}, privateKey, SignTypeMD5)
+	res, err := client.Verify(params)
+	asserts.NoError(err)
+	asserts.False(res.VerifyStatus)
+}
@@ -0,0 +1,191 @@
+package epay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Version 易支付接口版本
+type Version string
+
+const (
+	VersionV1   Version = "v1"   // 彩虹易支付V1接口（MD5签名）
+	VersionV2   Version = "v2"   // 彩虹易支付V2接口（RSA或MD5签名）
+	VersionAuto Version = "auto" // 首次请求时探测网关支持的接口版本
+)
+
+// 是否使用RSA签名（配置了平台公钥或验签器）
+func (c *Client) useRSA() bool {
+	return c.Config.PublicKey != "" || c.Config.RSAPublicKey != nil || c.Config.Verifier != nil
+}
+
+// V2接口请求使用的签名类型，未配置平台公钥时使用MD5
+func (c *Client) v2SignType() string {
+	if c.useRSA() {
+		return SignTypeRSA
+	}
+	return SignTypeMD5
+}
+
+// 获取实际使用的接口版本
+// 未配置 Config.Version 时沿用旧逻辑，配置了平台公钥即使用V2接口
+func (c *Client) version(ctx context.Context) (Version, error) {
+	switch c.Config.Version {
+	case VersionV1, VersionV2:
+		return c.Config.Version, nil
+	case VersionAuto:
+		return c.DetectVersion(ctx)
+	}
+	if c.useRSA() {
+		return VersionV2, nil
+	}
+	return VersionV1, nil
+}
+
+// 接口版本探测失败后，在该时间内直接返回上次的错误
+const versionRetryInterval = 10 * time.Second
+
+// 进行中的接口版本探测，并发调用共享同一次探测结果
+type versionProbe struct {
+	done    chan struct{}
+	version Version
+	err     error
+}
+
+// DetectVersion 探测网关支持的接口版本，探测成功后缓存结果
+// 网关的V2查询接口返回JSON状态码时视为支持V2，否则视为V1
+// 探测失败时在 versionRetryInterval 内不再重复探测，网关与密钥不匹配的错误始终缓存
+func (c *Client) DetectVersion(ctx context.Context) (Version, error) {
+	for {
+		c.versionMu.Lock()
+		if c.detectedVersion != "" {
+			c.versionMu.Unlock()
+			return c.detectedVersion, nil
+		}
+		if c.versionErr != nil && (c.versionErrUntil.IsZero() || time.Now().Before(c.versionErrUntil)) {
+			c.versionMu.Unlock()
+			return "", c.versionErr
+		}
+		probe := c.versionProbe
+		if probe == nil {
+			// 由当前调用发起探测，不持有锁进行网络请求
+			probe = &versionProbe{done: make(chan struct{})}
+			c.versionProbe = probe
+			c.versionMu.Unlock()
+			c.runVersionProbe(ctx, probe)
+			return probe.version, probe.err
+		}
+		c.versionMu.Unlock()
+
+		select {
+		case <-probe.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		// 其他调用方的ctx取消导致探测中断时，重新发起探测
+		if isContextError(probe.err) && ctx.Err() == nil {
+			continue
+		}
+		return probe.version, probe.err
+	}
+}
+
+// 执行探测并记录结果
+func (c *Client) runVersionProbe(ctx context.Context, probe *versionProbe) {
+	version, err := c.probeVersion(ctx)
+	var errUntil time.Time
+	switch {
+	case err != nil:
+		probe.err = fmt.Errorf("探测接口版本失败: %w", err)
+		errUntil = time.Now().Add(versionRetryInterval)
+	case version == VersionV1 && c.useRSA():
+		probe.err = errors.New("网关仅支持V1接口，V1接口不支持RSA签名")
+	default:
+		probe.version = version
+	}
+
+	c.versionMu.Lock()
+	c.versionProbe = nil
+	if probe.err == nil {
+		c.detectedVersion = version
+	} else if !isContextError(err) {
+		c.versionErr = probe.err
+		c.versionErrUntil = errUntil
+	}
+	c.versionMu.Unlock()
+	close(probe.done)
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// 向V2查询接口发送空请求，根据响应判断接口版本
+func (c *Client) probeVersion(ctx context.Context) (Version, error) {
+	apiUrl, err := c.apiUrl(V2QueryUrl)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiUrl.String(), strings.NewReader(""))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.getHTTPClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("网关响应异常: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+	var probe struct {
+		Code *json.Number `json:"code"`
+	}
+	if resp.StatusCode != http.StatusNotFound && json.Unmarshal(body, &probe) == nil && probe.Code != nil {
+		return VersionV2, nil
+	}
+	return VersionV1, nil
+}
+
+// 校验所选接口版本需要的密钥是否已配置
+// 不允许出现以空密钥进行MD5签名或验签的配置
+func (c *Client) validateConfig() error {
+	cfg := c.Config
+	if c.useRSA() {
+		if cfg.Signer == nil && cfg.RSAPrivateKey == nil && cfg.Key == "" {
+			return errors.New("使用RSA签名时需要配置商户私钥")
+		}
+	} else if cfg.Signer != nil {
+		return errors.New("配置签名器时需要同时配置平台公钥或验签器")
+	} else if cfg.RSAPrivateKey != nil {
+		return errors.New("使用RSA签名时需要配置平台公钥")
+	} else if cfg.Key == "" {
+		return errors.New("需要配置商户MD5密钥或RSA私钥")
+	}
+
+	switch cfg.Version {
+	case "", VersionV2, VersionAuto:
+	case VersionV1:
+		if c.useRSA() {
+			return errors.New("V1接口不支持RSA签名，请勿配置平台公钥")
+		}
+	default:
+		return fmt.Errorf("不支持的接口版本: %s", cfg.Version)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package epay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	asserts := assert.New(t)
+	privateKey, publicKey := genTestKeyPair(t)
+
+	_, err := NewClient(nil, "http://localhost")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000", Version: VersionV1}, "http://localhost")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey, Version: VersionV1}, "http://localhost")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000", PublicKey: publicKey, Version: VersionV2}, "http://localhost")
+	asserts.Error(err)
+	_, err = NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: "v3"}, "http://localhost")
+	asserts.Error(err)
+
+	// V2接口可使用MD5密钥
+	client, err := NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: VersionV2}, "http://localhost")
+	asserts.NoError(err)
+	asserts.Equal(SignTypeMD5, client.v2SignType())
+}
+
+func TestV2WithMD5Key(t *testing.T) {
+	asserts := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		asserts.Equal("/api/pay/query", r.URL.Path)
+		asserts.Equal(SignTypeMD5, r.PostForm.Get("sign_type"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":      0,
+			"trade_no":  "T1",
+			"money":     "1.00",
+			"status":    1,
+			"sign":      MD5String("code=0&money=1.00&status=1&trade_no=T1", "KEY"),
+			"sign_type": SignTypeMD5,
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: VersionV2}, server.URL)
+	asserts.NoError(err)
+	res, err := client.QueryOrder("T1", "")
+	asserts.NoError(err)
+	asserts.Equal(1, res.Status)
+
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "OTHER", Version: VersionV2}, server.URL)
+	_, err = client.QueryOrder("T1", "")
+	asserts.ErrorIs(err, ErrInvalidResponseSign)
+}
+
+func TestDetectVersion(t *testing.T) {
+	asserts := assert.New(t)
+	var probes int32
+	v2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		w.Write([]byte(`{"code":-1,"msg":"签名校验失败"}`))
+	}))
+	defer v2.Close()
+	v1 := httptest.NewServer(http.NotFoundHandler())
+	defer v1.Close()
+
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: VersionAuto}, v2.URL)
+	version, err := client.DetectVersion(context.Background())
+	asserts.NoError(err)
+	asserts.Equal(VersionV2, version)
+	version, _ = client.version(context.Background())
+	asserts.Equal(VersionV2, version)
+	asserts.Equal(int32(1), atomic.LoadInt32(&probes))
+
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: VersionAuto}, v1.URL)
+	version, err = client.DetectVersion(context.Background())
+	asserts.NoError(err)
+	asserts.Equal(VersionV1, version)
+
+	// V1网关不支持RSA签名
+	privateKey, publicKey := genTestKeyPair(t)
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey, Version: VersionAuto}, v1.URL)
+	_, err = client.DetectVersion(context.Background())
+	asserts.Error(err)
+}
+
+func TestDetectVersionDoesNotBlock(t *testing.T) {
+	asserts := assert.New(t)
+	release := make(chan struct{})
+	var probes int32
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		<-release
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer hung.Close()
+	defer close(release)
+
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: VersionAuto}, hung.URL)
+	go client.DetectVersion(context.Background())
+	for atomic.LoadInt32(&probes) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 探测进行中时，其他调用按自身ctx超时返回
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := client.CreateOrderWithContext(ctx, &CreateOrderArgs{})
+	asserts.ErrorIs(err, context.DeadlineExceeded)
+	asserts.Less(time.Since(start), time.Second)
+}
+
+func TestDetectVersionCachesFailure(t *testing.T) {
+	asserts := assert.New(t)
+	var probes int32
+	v1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		http.NotFound(w, r)
+	}))
+	defer v1.Close()
+
+	privateKey, publicKey := genTestKeyPair(t)
+	client, _ := NewClient(&Config{PartnerID: "1000", Key: privateKey, PublicKey: publicKey, Version: VersionAuto}, v1.URL)
+	_, err := client.DetectVersion(context.Background())
+	asserts.Error(err)
+	_, err = client.QueryOrder("T1", "")
+	asserts.Error(err)
+	asserts.Equal(int32(1), atomic.LoadInt32(&probes))
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	client, _ = NewClient(&Config{PartnerID: "1000", Key: "KEY", Version: VersionAuto}, broken.URL)
+	_, err = client.DetectVersion(context.Background())
+	asserts.Error(err)
+	_, err = client.DetectVersion(context.Background())
+	asserts.Error(err)
+	asserts.Equal(int32(2), atomic.LoadInt32(&probes))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
@@ -136,7 +137,7 @@ func testCreateV2Order() error {
 		NotifyURL:  notifyUrl,
 		ReturnURL:  returnUrl,
 		Name:       "V2测试商品",
-		Money:      "2.5", // 测试金额
+		Money:      epay.MustParseAmount("2.50"), // 测试金额
 		ClientIP:   "127.0.0.1",
 	}
 	if publicKey != "" {
@@ -144,24 +145,24 @@ func testCreateV2Order() error {
 	}
 	result, err := client.ApiCreateOrder(orderArgs)
 
+	// 平台返回失败时为 *epay.APIError，无需再判断状态码
+	var apiErr *epay.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("创建订单失败: 状态码 %d, 消息 %s", apiErr.Code, apiErr.Message)
+	}
 	if err != nil {
 		return fmt.Errorf("创建订单失败: %v", err)
 	}
 
-	fmt.Printf("API创建订单结果:\n- 状态码: %d\n- 消息: %s\n", result.Code, result.Message)
-
-	// V2接口成功返回码是0
-	if result.Code == 0 {
-		fmt.Println("创建订单成功!")
-		if result.PayURL != "" {
-			fmt.Println("支付URL:", result.PayURL)
-		}
-		if result.QRCode != "" {
-			fmt.Println("二维码链接:", result.QRCode)
-		}
-		if result.PayInfo != "" {
-			fmt.Println("支付参数:", result.PayInfo)
-		}
+	fmt.Println("创建订单成功!")
+	if result.PayURL != "" {
+		fmt.Println("支付URL:", result.PayURL)
+	}
+	if result.QRCode != "" {
+		fmt.Println("二维码链接:", result.QRCode)
+	}
+	if result.PayInfo != "" {
+		fmt.Println("支付参数:", result.PayInfo)
 	}
 
 	return nil
@@ -197,30 +198,30 @@ func testCreateV1Order() error {
 		NotifyURL:  notifyUrl,
 		ReturnURL:  returnUrl,
 		Name:       "V1测试商品",
-		Money:      "2.5", // 测试金额
+		Money:      epay.MustParseAmount("2.50"), // 测试金额
 		ClientIP:   "127.0.0.1",
 	}
 
 	result, err := client.ApiCreateOrder(orderArgs)
 
+	// 平台返回失败时为 *epay.APIError，无需再判断状态码
+	var apiErr *epay.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("创建订单失败: 状态码 %d, 消息 %s", apiErr.Code, apiErr.Message)
+	}
 	if err != nil {
 		return fmt.Errorf("创建订单失败: %v", err)
 	}
 
-	fmt.Printf("API创建订单结果:\n- 状态码: %d\n- 消息: %s\n", result.Code, result.Message)
-
-	// V2接口成功返回码是0
-	if result.Code == 0 {
-		fmt.Println("创建订单成功!")
-		if result.PayURL != "" {
-			fmt.Println("支付URL:", result.PayURL)
-		}
-		if result.QRCode != "" {
-			fmt.Println("二维码链接:", result.QRCode)
-		}
-		if result.PayInfo != "" {
-			fmt.Println("支付参数:", result.PayInfo)
-		}
+	fmt.Println("创建订单成功!")
+	if result.PayURL != "" {
+		fmt.Println("支付URL:", result.PayURL)
+	}
+	if result.QRCode != "" {
+		fmt.Println("二维码链接:", result.QRCode)
+	}
+	if result.PayInfo != "" {
+		fmt.Println("支付参数:", result.PayInfo)
 	}
 
 	return nil
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"html"
 	"log"
 	"net/http"
 	"net/url"
@@ -9,7 +12,6 @@ import (
 	"time"
 
 	"github.com/popdo/go-epay/epay"
-	"github.com/samber/lo"
 )
 
 func main() {
@@ -24,16 +26,17 @@ func main() {
 		log.Panicln(err)
 	}
 	notify, _ := url.Parse(baseUrl + "/verify")
+	returnUrl, _ := url.Parse(baseUrl + "/return")
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
 		url, params, err := client.CreateOrder(&epay.CreateOrderArgs{
 			Type:       "wxpay",
 			OutTradeNo: "8412317576584121",
 			Name:       "test",
-			Money:      "0.01",
+			Money:      epay.MustParseAmount("0.01"),
 			Device:     epay.PC,
 			NotifyUrl:  notify,
-			ReturnUrl:  notify,
+			ReturnUrl:  returnUrl,
 		})
 		if err != nil {
 			log.Println(err)
@@ -63,11 +66,11 @@ func main() {
 			Type:       "wxpay",
 			OutTradeNo: "API" + time.Now().Format("20060102150405"),
 			Name:       "API支付测试",
-			Money:      "0.01",
+			Money:      epay.MustParseAmount("0.01"),
 			ClientIP:   clientIP,
 			Device:     epay.PC,
 			NotifyURL:  notify,
-			ReturnURL:  notify,
+			ReturnURL:  returnUrl,
 		})
 
 		if err != nil {
@@ -80,14 +83,12 @@ func main() {
 		json.NewEncoder(writer).Encode(result)
 
 		// 如果需要显示二维码或跳转
-		if result.Code == 1 {
-			if result.QRCode != "" {
-				log.Println("生成二维码:", result.QRCode)
-			} else if result.PayURL != "" {
-				log.Println("跳转支付URL:", result.PayURL)
-			} else if result.URLScheme != "" {
-				log.Println("小程序URL:", result.URLScheme)
-			}
+		if result.QRCode != "" {
+			log.Println("生成二维码:", result.QRCode)
+		} else if result.PayURL != "" {
+			log.Println("跳转支付URL:", result.PayURL)
+		} else if result.URLScheme != "" {
+			log.Println("小程序URL:", result.URLScheme)
 		}
 	})
 
@@ -101,6 +102,10 @@ func main() {
 		}
 
 		result, err := client.QueryOrder("", outTradeNo)
+		if errors.Is(err, epay.ErrOrderNotFound) {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			log.Println(err)
 			writer.WriteHeader(http.StatusInternalServerError)
@@ -111,27 +116,26 @@ func main() {
 		json.NewEncoder(writer).Encode(result)
 
 		// 如果订单支付成功，进行后续处理
-		if result.Code == 1 && result.Status == 1 {
+		if result.Status == 1 {
 			log.Printf("订单 %s 支付成功", outTradeNo)
 			// 处理业务逻辑
 		}
 	})
-	mux.HandleFunc("/verify", func(writer http.ResponseWriter, request *http.Request) {
-		params := lo.Reduce(lo.Keys(request.URL.Query()), func(r map[string]string, t string, i int) map[string]string {
-			r[t] = request.URL.Query().Get(t)
-			return r
-		}, map[string]string{})
-
-		verifyInfo, err := client.Verify(params)
-		if err == nil && verifyInfo.VerifyStatus {
-			writer.Write([]byte("success"))
-		} else {
-			writer.Write([]byte("fail"))
-		}
-
-		if verifyInfo.TradeStatus == epay.StatusTradeSuccess {
-			log.Println(verifyInfo)
+	mux.Handle("/verify", epay.NotifyHandler(client, func(ctx context.Context, verifyInfo *epay.VerifyRes) error {
+		// 仅在验签通过且支付成功时调用，返回错误会应答fail以便平台重新通知
+		log.Println(verifyInfo)
+		return nil
+	}))
+	// 支付完成后浏览器同步跳转，验签通过才展示支付结果
+	mux.Handle("/return", epay.ReturnMiddleware(client, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		verifyInfo, _ := epay.ReturnFromContext(request.Context())
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// 签名有效不代表已支付，需检查交易状态；最终结果以异步通知或查询订单为准
+		if verifyInfo.TradeStatus != epay.StatusTradeSuccess {
+			writer.Write([]byte("订单 " + html.EscapeString(verifyInfo.OutTradeNo) + " 尚未支付"))
+			return
 		}
-	})
+		writer.Write([]byte("订单 " + html.EscapeString(verifyInfo.OutTradeNo) + " 支付成功"))
+	})))
 	http.ListenAndServe(":8080", mux)
 }